@@ -0,0 +1,101 @@
+package s3client
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyendpoints "github.com/aws/smithy-go/endpoints"
+)
+
+// AddressingStyle selects how the bucket name is placed in request URLs.
+type AddressingStyle string
+
+const (
+	// AddressingAuto uses the provider preset, or for the generic
+	// provider picks virtual-hosted style for AWS endpoints and path
+	// style for everything else.
+	AddressingAuto    AddressingStyle = ""
+	AddressingPath    AddressingStyle = "path"
+	AddressingVirtual AddressingStyle = "virtual"
+)
+
+func (c Config) usePathStyle(profile providerProfile) bool {
+	switch c.AddressingStyle {
+	case AddressingPath:
+		return true
+	case AddressingVirtual:
+		return false
+	}
+	if c.Provider != ProviderGeneric {
+		return profile.pathStyle
+	}
+	return !isAWSEndpoint(c.Endpoint)
+}
+
+func isAWSEndpoint(endpoint string) bool {
+	if endpoint == "" {
+		return true
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	return strings.HasSuffix(host, ".amazonaws.com") || strings.HasSuffix(host, ".amazonaws.com.cn")
+}
+
+// isAccessPointARN reports whether bucket is an access point or
+// Multi-Region Access Point ARN rather than a bucket name.
+func isAccessPointARN(bucket string) bool {
+	if !arn.IsARN(bucket) {
+		return false
+	}
+	a, err := arn.Parse(bucket)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(a.Resource, "accesspoint/") || strings.HasPrefix(a.Resource, "accesspoint:")
+}
+
+// EndpointFunc returns the endpoint URL for requests to bucket, which is
+// empty for operations such as ListBuckets. Returning "" keeps the client
+// endpoint.
+type EndpointFunc func(bucket, region string) (string, error)
+
+// endpointResolver applies Config.EndpointResolver and turns off
+// path-style addressing for calls whose bucket is an access point ARN,
+// which the endpoint rules reject.
+type endpointResolver struct {
+	next    s3.EndpointResolverV2
+	custom  EndpointFunc
+	cfg     Config
+	profile providerProfile
+}
+
+func (r *endpointResolver) ResolveEndpoint(ctx context.Context, params s3.EndpointParameters) (smithyendpoints.Endpoint, error) {
+	bucket := aws.ToString(params.Bucket)
+	if r.custom != nil {
+		endpoint, err := r.custom(bucket, aws.ToString(params.Region))
+		if err != nil {
+			return smithyendpoints.Endpoint{}, err
+		}
+		if endpoint != "" {
+			params.Endpoint = aws.String(endpoint)
+			cfg := r.cfg
+			cfg.Endpoint = endpoint
+			params.ForcePathStyle = aws.Bool(cfg.usePathStyle(r.profile))
+		}
+	}
+	if isAccessPointARN(bucket) {
+		params.ForcePathStyle = aws.Bool(false)
+	}
+	return r.next.ResolveEndpoint(ctx, params)
+}
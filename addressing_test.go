@@ -0,0 +1,190 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	testAccessPoint = "arn:aws:s3:us-west-2:123456789012:accesspoint/myap"
+	testMRAP        = "arn:aws:s3::123456789012:accesspoint/mfzwi23gnjvgw.mrap"
+	testObjectLamba = "arn:aws:s3-object-lambda:us-west-2:123456789012:accesspoint/olap"
+)
+
+func TestIsAccessPointARN(t *testing.T) {
+	tests := []struct {
+		bucket string
+		want   bool
+	}{
+		{"my-bucket", false},
+		{"", false},
+		{testAccessPoint, true},
+		{"arn:aws:s3:us-west-2:123456789012:accesspoint:myap", true},
+		{testMRAP, true},
+		{testObjectLamba, true},
+		{"arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904/accesspoint/myap", false},
+		{"arn:aws:s3:::my-bucket", false},
+		{"arn:aws:s3:us-west-2", false},
+	}
+	for _, tt := range tests {
+		if got := isAccessPointARN(tt.bucket); got != tt.want {
+			t.Errorf("isAccessPointARN(%q) = %v, want %v", tt.bucket, got, tt.want)
+		}
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	tests := []struct {
+		bucket, key, want string
+	}{
+		{"b", "k", "b/k"},
+		{"b", "dir/a b+c.txt", "b/dir/a%20b+c.txt"},
+		{"b", "a?b#c", "b/a%3Fb%23c"},
+		{testAccessPoint, "dir/k", testAccessPoint + "/object/dir/k"},
+		{testMRAP, "a b", testMRAP + "/object/a%20b"},
+	}
+	for _, tt := range tests {
+		if got := copySource(tt.bucket, tt.key); got != tt.want {
+			t.Errorf("copySource(%q, %q) = %q, want %q", tt.bucket, tt.key, got, tt.want)
+		}
+	}
+}
+
+// captureTransport records requests and answers them with an empty 200,
+// after reading the body as a server would.
+type captureTransport struct {
+	mu   sync.Mutex
+	reqs []*http.Request
+}
+
+func (c *captureTransport) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	c.mu.Lock()
+	c.reqs = append(c.reqs, req)
+	c.mu.Unlock()
+	body := ""
+	if req.Method == http.MethodGet && req.URL.Query().Get("list-type") == "2" {
+		body = `<ListBucketResult><IsTruncated>false</IsTruncated><Contents><Key>dir/k</Key><Size>1</Size></Contents></ListBucketResult>`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Length": {"0"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (c *captureTransport) last(t *testing.T) *http.Request {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.reqs) == 0 {
+		t.Fatal("no request sent")
+	}
+	return c.reqs[len(c.reqs)-1]
+}
+
+func newCaptureClient(t *testing.T, cfg Config) (*Client, *captureTransport) {
+	t.Helper()
+	// The shared config would otherwise apply a CA bundle from the
+	// environment to the client's own HTTP client, which it cannot.
+	t.Setenv("AWS_CA_BUNDLE", "")
+	cfg.AccessKeyID, cfg.SecretAccessKey = "AKID", "secret"
+	if cfg.Region == "" {
+		cfg.Region = "us-west-2"
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capture := &captureTransport{}
+	opts := c.s3Client.Options()
+	opts.HTTPClient = capture
+	c.s3Client = s3.New(opts)
+	return c, capture
+}
+
+// credentialScope returns the region and service of a SigV4 Authorization
+// header.
+func credentialScope(auth string) (region, service string) {
+	_, cred, ok := strings.Cut(auth, "Credential=")
+	if !ok {
+		return "", ""
+	}
+	cred, _, _ = strings.Cut(cred, ",")
+	parts := strings.Split(cred, "/")
+	if len(parts) < 5 {
+		return "", ""
+	}
+	return parts[2], parts[3]
+}
+
+func TestAccessPointRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		bucket  string
+		host    string
+		region  string
+		service string
+		sigv4a  bool
+	}{
+		{name: "access point", bucket: testAccessPoint,
+			host: "myap-123456789012.s3-accesspoint.us-west-2.amazonaws.com", region: "us-west-2", service: "s3"},
+		{name: "access point with path style", cfg: Config{AddressingStyle: AddressingPath}, bucket: testAccessPoint,
+			host: "myap-123456789012.s3-accesspoint.us-west-2.amazonaws.com", region: "us-west-2", service: "s3"},
+		{name: "object lambda", bucket: testObjectLamba,
+			host: "olap-123456789012.s3-object-lambda.us-west-2.amazonaws.com", region: "us-west-2", service: "s3-object-lambda"},
+		{name: "multi-region", bucket: testMRAP,
+			host: "mfzwi23gnjvgw.mrap.accesspoint.s3-global.amazonaws.com", sigv4a: true},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, capture := newCaptureClient(t, tt.cfg)
+			check := func(op, method, path string) {
+				t.Helper()
+				req := capture.last(t)
+				if req.Method != method || req.URL.Host != tt.host || req.URL.Path != path {
+					t.Errorf("%s: %s %s%s, want %s %s%s", op, req.Method, req.URL.Host, req.URL.Path, method, tt.host, path)
+				}
+				auth := req.Header.Get("Authorization")
+				if tt.sigv4a {
+					if !strings.HasPrefix(auth, "AWS4-ECDSA-P256-SHA256 ") || req.Header.Get("X-Amz-Region-Set") != "*" {
+						t.Errorf("%s: not signed with SigV4a for all regions: %q", op, auth)
+					}
+					return
+				}
+				if region, service := credentialScope(auth); region != tt.region || service != tt.service {
+					t.Errorf("%s: signed for %s/%s, want %s/%s", op, region, service, tt.region, tt.service)
+				}
+			}
+
+			if err := c.PutObjectBytes(ctx, tt.bucket, "dir/k", []byte("x"), "text/plain"); err != nil {
+				t.Fatal(err)
+			}
+			check("put", http.MethodPut, "/dir/k")
+			if _, err := c.GetObjectBytes(ctx, tt.bucket, "dir/k"); err != nil {
+				t.Fatal(err)
+			}
+			check("get", http.MethodGet, "/dir/k")
+			keys, err := c.ListObjects(ctx, tt.bucket, "dir/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			check("list", http.MethodGet, "/")
+			if len(keys) != 1 || keys[0] != "dir/k" {
+				t.Errorf("list = %v", keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Package admin manages MinIO users, policies, service accounts and bucket
+// quotas through the MinIO admin API, using the same Config as the object
+// client.
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mkchar/s3client"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const adminPath = "/minio/admin/v3/"
+
+// Error is returned for non-2xx admin API responses.
+type Error struct {
+	StatusCode int
+	Code       string `json:"Code"`
+	Message    string `json:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3client/admin: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("s3client/admin: %s: %s", e.Code, e.Message)
+}
+
+type Client struct {
+	endpoint *url.URL
+	region   string
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	http     *http.Client
+	headers  map[string]string
+}
+
+// New returns an admin client for cfg.Endpoint, signed with the same
+// credentials and region and sent over the same transport settings and
+// default headers as an s3client built from cfg.
+func New(cfg s3client.Config) (*Client, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("s3client/admin: Config.Endpoint is required")
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	var creds aws.CredentialsProvider = credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	if cfg.Credentials != nil {
+		creds = cfg.Credentials
+	}
+	httpClient, err := s3client.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Timeout = time.Minute
+	return &Client{
+		endpoint: u,
+		region:   region,
+		creds:    aws.NewCredentialsCache(creds),
+		signer:   v4.NewSigner(),
+		http:     httpClient,
+		headers:  cfg.DefaultHeaders,
+	}, nil
+}
+
+// do signs and sends one admin API request. When encrypt is set, body is
+// encrypted with the caller's secret key first.
+func (c *Client) do(ctx context.Context, method, op string, query url.Values, body []byte, encrypt bool) ([]byte, error) {
+	creds, err := c.creds.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if encrypt {
+		if body, err = encryptData(creds.SecretAccessKey, body); err != nil {
+			return nil, err
+		}
+	}
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + adminPath + op
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if err := c.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", c.region, time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(data, apiErr)
+		return nil, apiErr
+	}
+	return data, nil
+}
+
+// AddUser creates or updates a user with the given secret key.
+func (c *Client) AddUser(ctx context.Context, accessKey, secretKey string) error {
+	body, err := json.Marshal(map[string]string{"secretKey": secretKey, "status": "enabled"})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, http.MethodPut, "add-user", url.Values{"accessKey": {accessKey}}, body, true)
+	return err
+}
+
+func (c *Client) RemoveUser(ctx context.Context, accessKey string) error {
+	_, err := c.do(ctx, http.MethodDelete, "remove-user", url.Values{"accessKey": {accessKey}}, nil, false)
+	return err
+}
+
+func (c *Client) SetUserEnabled(ctx context.Context, accessKey string, enabled bool) error {
+	status := "disabled"
+	if enabled {
+		status = "enabled"
+	}
+	_, err := c.do(ctx, http.MethodPut, "set-user-status", url.Values{"accessKey": {accessKey}, "status": {status}}, nil, false)
+	return err
+}
+
+// AddPolicy creates or replaces the canned policy name with an IAM policy
+// document.
+func (c *Client) AddPolicy(ctx context.Context, name string, policy []byte) error {
+	_, err := c.do(ctx, http.MethodPut, "add-canned-policy", url.Values{"name": {name}}, policy, false)
+	return err
+}
+
+func (c *Client) RemovePolicy(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodDelete, "remove-canned-policy", url.Values{"name": {name}}, nil, false)
+	return err
+}
+
+// ListPolicies returns the canned policies by name.
+func (c *Client) ListPolicies(ctx context.Context) (map[string]json.RawMessage, error) {
+	data, err := c.do(ctx, http.MethodGet, "list-canned-policies", nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	var policies map[string]json.RawMessage
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// AttachPolicy sets the policies of a user, replacing any attached before.
+func (c *Client) AttachPolicy(ctx context.Context, accessKey string, policies ...string) error {
+	return c.setPolicy(ctx, accessKey, false, policies)
+}
+
+// AttachGroupPolicy sets the policies of a group.
+func (c *Client) AttachGroupPolicy(ctx context.Context, group string, policies ...string) error {
+	return c.setPolicy(ctx, group, true, policies)
+}
+
+func (c *Client) setPolicy(ctx context.Context, entity string, group bool, policies []string) error {
+	query := url.Values{
+		"policyName":  {strings.Join(policies, ",")},
+		"userOrGroup": {entity},
+		"isGroup":     {fmt.Sprint(group)},
+	}
+	_, err := c.do(ctx, http.MethodPut, "set-user-or-group-policy", query, nil, false)
+	return err
+}
+
+// ServiceAccount describes a service account to create. Empty keys are
+// generated; a nil Policy inherits the parent user's policies.
+type ServiceAccount struct {
+	AccessKey   string          `json:"accessKey,omitempty"`
+	SecretKey   string          `json:"secretKey,omitempty"`
+	TargetUser  string          `json:"targetUser,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Policy      json.RawMessage `json:"policy,omitempty"`
+	Expiration  *time.Time      `json:"expiration,omitempty"`
+}
+
+// AddServiceAccount creates sa and returns it with the keys filled in.
+// TargetUser defaults to the caller.
+func (c *Client) AddServiceAccount(ctx context.Context, sa ServiceAccount) (ServiceAccount, error) {
+	if sa.AccessKey == "" {
+		sa.AccessKey = rand.Text()[:20]
+	}
+	if sa.SecretKey == "" {
+		sa.SecretKey = (rand.Text() + rand.Text())[:40]
+	}
+	body, err := json.Marshal(sa)
+	if err != nil {
+		return ServiceAccount{}, err
+	}
+	if _, err := c.do(ctx, http.MethodPut, "add-service-account", nil, body, true); err != nil {
+		return ServiceAccount{}, err
+	}
+	return sa, nil
+}
+
+func (c *Client) DeleteServiceAccount(ctx context.Context, accessKey string) error {
+	_, err := c.do(ctx, http.MethodDelete, "delete-service-account", url.Values{"accessKey": {accessKey}}, nil, false)
+	return err
+}
+
+type bucketQuota struct {
+	Quota uint64 `json:"quota"`
+	Size  uint64 `json:"size"`
+	Type  string `json:"quotatype,omitempty"`
+}
+
+// SetBucketQuota sets a hard quota of size bytes on bucket. A zero size
+// removes the quota.
+func (c *Client) SetBucketQuota(ctx context.Context, bucket string, size uint64) error {
+	q := bucketQuota{Quota: size, Size: size}
+	if size > 0 {
+		q.Type = "hard"
+	}
+	body, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, http.MethodPut, "set-bucket-quota", url.Values{"bucket": {bucket}}, body, false)
+	return err
+}
+
+// GetBucketQuota returns the quota of bucket in bytes, or 0 without one.
+func (c *Client) GetBucketQuota(ctx context.Context, bucket string) (uint64, error) {
+	data, err := c.do(ctx, http.MethodGet, "get-bucket-quota", url.Values{"bucket": {bucket}}, nil, false)
+	if err != nil {
+		return 0, err
+	}
+	var q bucketQuota
+	if err := json.Unmarshal(data, &q); err != nil {
+		return 0, err
+	}
+	return max(q.Size, q.Quota), nil
+}
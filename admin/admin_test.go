@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mkchar/s3client"
+)
+
+func TestNewUsesTransportAndDefaultHeaders(t *testing.T) {
+	var tenant string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(s3client.Config{
+		Endpoint:        srv.URL,
+		AccessKeyID:     "a",
+		SecretAccessKey: "b",
+		Transport:       &s3client.TransportConfig{CAFile: caFile},
+		DefaultHeaders:  map[string]string{"X-Tenant": "t1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.do(context.Background(), http.MethodGet, "info", nil, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if tenant != "t1" {
+		t.Errorf("X-Tenant = %q, want t1", tenant)
+	}
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// MinIO expects credential-bearing request bodies encrypted with the
+// caller's secret key in the madmin format: a 32-byte salt, an algorithm
+// ID, an 8-byte nonce and a sio stream. Only the PBKDF2 / AES-256-GCM
+// variant is produced, as it needs nothing outside the standard library.
+const (
+	pbkdf2AESGCM   = 0x02
+	pbkdf2Cost     = 8192
+	sioFragment    = 1 << 14
+	sioFinalMarker = 0x80
+)
+
+func encryptData(password string, data []byte) ([]byte, error) {
+	salt, nonce := make([]byte, 32), make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return encryptWith(password, salt, nonce, data)
+}
+
+func encryptWith(password string, salt, nonce, data []byte) ([]byte, error) {
+	header := make([]byte, 0, 32+1+8)
+	header = append(header, salt...)
+	header = append(header, pbkdf2AESGCM)
+	header = append(header, nonce...)
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, pbkdf2Cost, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each fragment is sealed under nonce||seq, with the stream's
+	// associated data bound in by a tag sealed at sequence number 0 and the
+	// last fragment flagged in the first byte.
+	fullNonce := make([]byte, aead.NonceSize())
+	copy(fullNonce, nonce)
+	ad := make([]byte, 1+aead.Overhead())
+	aead.Seal(ad[1:1], fullNonce, nil, nil)
+
+	out := header
+	seq := uint32(1)
+	for {
+		n := min(len(data), sioFragment)
+		last := n == len(data)
+		if last {
+			ad[0] = sioFinalMarker
+		}
+		binary.LittleEndian.PutUint32(fullNonce[len(fullNonce)-4:], seq)
+		out = aead.Seal(out, fullNonce, data[:n], ad)
+		data = data[n:]
+		seq++
+		if last {
+			return out, nil
+		}
+	}
+}
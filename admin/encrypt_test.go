@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// decryptData follows madmin.DecryptData for the PBKDF2 / AES-256-GCM
+// variant, reading the sio stream fragment by fragment.
+func decryptData(password string, data []byte) ([]byte, error) {
+	if len(data) < 41 || data[32] != pbkdf2AESGCM {
+		return nil, errors.New("bad header")
+	}
+	salt, nonce, data := data[:32], data[33:41], data[41:]
+	key, err := pbkdf2.Key(sha256.New, password, salt, pbkdf2Cost, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	fullNonce := make([]byte, aead.NonceSize())
+	copy(fullNonce, nonce)
+	ad := make([]byte, 1+aead.Overhead())
+	aead.Seal(ad[1:1], fullNonce, nil, nil)
+
+	var out []byte
+	for seq := uint32(1); ; seq++ {
+		n := min(len(data), sioFragment+aead.Overhead())
+		if n == len(data) {
+			ad[0] = sioFinalMarker
+		}
+		binary.LittleEndian.PutUint32(fullNonce[len(fullNonce)-4:], seq)
+		out, err = aead.Open(out, fullNonce, data[:n], ad)
+		if err != nil {
+			return nil, err
+		}
+		if data = data[n:]; len(data) == 0 {
+			return out, nil
+		}
+	}
+}
+
+func TestEncryptDataRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, sioFragment - 1, sioFragment, sioFragment + 1, 3*sioFragment + 7} {
+		plain := bytes.Repeat([]byte{'x'}, size)
+		enc, err := encryptData("secret", plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fragments := max(1, (size+sioFragment-1)/sioFragment)
+		if want := 41 + size + fragments*16; len(enc) != want {
+			t.Errorf("size %d: ciphertext is %d bytes, want %d", size, len(enc), want)
+		}
+		got, err := decryptData("secret", enc)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestEncryptDataRejectsTampering(t *testing.T) {
+	enc, err := encryptData("secret", bytes.Repeat([]byte{'x'}, 2*sioFragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decryptData("other", enc); err == nil {
+		t.Error("wrong password decrypted")
+	}
+	// Dropping the final fragment leaves a stream without its final flag.
+	if _, err := decryptData("secret", enc[:41+sioFragment+16]); err == nil {
+		t.Error("truncated stream decrypted")
+	}
+}
+
+// TestEncryptWithKnownAnswer pins the output for a fixed salt and nonce so
+// changes to the stream layout are caught.
+func TestEncryptWithKnownAnswer(t *testing.T) {
+	salt := bytes.Repeat([]byte{1}, 32)
+	nonce := bytes.Repeat([]byte{2}, 8)
+	enc, err := encryptWith("secret", salt, nonce, []byte(`{"secretKey":"abc"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc[:32], salt) || enc[32] != pbkdf2AESGCM || !bytes.Equal(enc[33:41], nonce) {
+		t.Fatalf("header = %x", enc[:41])
+	}
+	const want = "82e442cf98599509632651567fb28eeec2a2ddaaf6f860a7be26ac788133d9558be8a8"
+	if got := hex.EncodeToString(enc[41:]); got != want {
+		t.Errorf("ciphertext = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package s3client
+
+import (
+	"context"
+	"io"
+)
+
+// ObjectAPI is the object-level part of Client, for code that wants to
+// accept a decorated or fake client such as Chaos.
+type ObjectAPI interface {
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string, opts ...CallOption) error
+	PutObjectBytes(ctx context.Context, bucket, key string, data []byte, contentType string, opts ...CallOption) error
+	GetObject(ctx context.Context, bucket, key string, opts ...CallOption) (io.ReadCloser, error)
+	GetObjectBytes(ctx context.Context, bucket, key string, opts ...CallOption) ([]byte, error)
+	StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error)
+	ObjectExists(ctx context.Context, bucket, key string) (bool, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+	DeleteObjects(ctx context.Context, bucket string, keys []string) error
+	CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error
+	ListObjects(ctx context.Context, bucket, prefix string) ([]string, error)
+	ListObjectsDetailed(ctx context.Context, bucket, prefix string, opts ListOptions) ([]ObjectInfo, error)
+	UploadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error
+	DownloadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error
+}
+
+var _ ObjectAPI = (*Client)(nil)
@@ -0,0 +1,97 @@
+package s3client
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ArchivePrefix streams every object under prefix into a tar archive written
+// to w. Entry names are the keys relative to prefix. WithCompression wraps
+// the archive in gzip or zstd.
+func (c *Client) ArchivePrefix(ctx context.Context, bucket, prefix string, w io.Writer, opts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, opts)
+	if o.compression != CompressionNone {
+		enc, err := newCompressWriter(w, o.compression)
+		if err != nil {
+			return err
+		}
+		if err := c.writeTar(ctx, bucket, prefix, enc); err != nil {
+			enc.Close()
+			return err
+		}
+		return enc.Close()
+	}
+	return c.writeTar(ctx, bucket, prefix, w)
+}
+
+// ArchivePrefixToObject writes the archive produced by ArchivePrefix to
+// dstBucket/dstKey without staging it locally.
+func (c *Client) ArchivePrefixToObject(ctx context.Context, bucket, prefix, dstBucket, dstKey string, opts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, opts)
+	contentType := "application/x-tar"
+	switch o.compression {
+	case CompressionGzip:
+		contentType = "application/gzip"
+	case CompressionZstd:
+		contentType = "application/zstd"
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.ArchivePrefix(ctx, bucket, prefix, pw, opts...))
+	}()
+	defer pr.Close()
+
+	_, err := c.upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(dstBucket),
+		Key:         aws.String(dstKey),
+		Body:        pr,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (c *Client) writeTar(ctx context.Context, bucket, prefix string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		name := strings.TrimPrefix(*obj.Key, prefix)
+		if name == "" || strings.HasSuffix(name, "/") {
+			return nil
+		}
+		return c.writeTarEntry(ctx, tw, bucket, *obj.Key, name)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func (c *Client) writeTarEntry(ctx context.Context, tw *tar.Writer, bucket, key, name string) error {
+	output, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+
+	hdr := &tar.Header{
+		Name:     name,
+		Mode:     0o644,
+		Size:     aws.ToInt64(output.ContentLength),
+		ModTime:  aws.ToTime(output.LastModified),
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, output.Body)
+	return err
+}
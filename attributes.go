@@ -0,0 +1,71 @@
+package s3client
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ObjectAttributes is the result of GetObjectAttributes. Parts holds
+// every part of a multipart object, with their checksums when the
+// object was uploaded with one.
+type ObjectAttributes struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+	StorageClass string
+	VersionID    string
+	Checksum     types.Checksum
+	TotalParts   int32
+	Parts        []types.ObjectPart
+}
+
+func (c *Client) GetObjectAttributes(ctx context.Context, bucket, key string) (ObjectAttributes, error) {
+	attrs := ObjectAttributes{Key: key}
+	input := &s3.GetObjectAttributesInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		ObjectAttributes: []types.ObjectAttributes{
+			types.ObjectAttributesEtag,
+			types.ObjectAttributesChecksum,
+			types.ObjectAttributesObjectParts,
+			types.ObjectAttributesStorageClass,
+			types.ObjectAttributesObjectSize,
+		},
+		MaxParts: aws.Int32(1000),
+	}
+	for {
+		output, err := c.s3Client.GetObjectAttributes(ctx, input)
+		if err != nil {
+			return ObjectAttributes{}, err
+		}
+		if input.PartNumberMarker == nil {
+			attrs.Size = aws.ToInt64(output.ObjectSize)
+			attrs.ETag = aws.ToString(output.ETag)
+			attrs.LastModified = aws.ToTime(output.LastModified)
+			attrs.StorageClass = string(output.StorageClass)
+			attrs.VersionID = aws.ToString(output.VersionId)
+			if output.Checksum != nil {
+				attrs.Checksum = *output.Checksum
+			}
+		}
+		parts := output.ObjectParts
+		if parts == nil {
+			return attrs, nil
+		}
+		attrs.TotalParts = aws.ToInt32(parts.TotalPartsCount)
+		attrs.Parts = append(attrs.Parts, parts.Parts...)
+		if !aws.ToBool(parts.IsTruncated) || parts.NextPartNumberMarker == nil {
+			return attrs, nil
+		}
+		input.PartNumberMarker = parts.NextPartNumberMarker
+		// Pin later pages to the version the first page described.
+		if attrs.VersionID != "" {
+			input.VersionId = aws.String(attrs.VersionID)
+		}
+	}
+}
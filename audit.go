@@ -0,0 +1,227 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AuditEvent records one mutating or presign operation.
+type AuditEvent struct {
+	Time      time.Time     `json:"time"`
+	Principal string        `json:"principal"`
+	Operation string        `json:"operation"`
+	Bucket    string        `json:"bucket,omitempty"`
+	Key       string        `json:"key,omitempty"`
+	Keys      []string      `json:"keys,omitempty"`
+	Outcome   string        `json:"outcome"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+type AuditSink interface {
+	Record(AuditEvent)
+}
+
+type AuditFunc func(AuditEvent)
+
+func (f AuditFunc) Record(e AuditEvent) { f(e) }
+
+type AuditConfig struct {
+	Sink AuditSink
+	// Principal names the caller recorded with each event. It defaults to
+	// the access key ID of the client credentials.
+	Principal func(ctx context.Context) string
+}
+
+// auditSkipped reports whether op is left out of the audit log. Multipart
+// uploads are recorded when they complete or are aborted.
+func auditSkipped(op string) bool {
+	switch op {
+	case "CreateMultipartUpload", "UploadPart", "UploadPartCopy":
+		return true
+	}
+	for _, p := range []string{"Put", "Delete", "Copy", "Create", "Complete", "Abort", "Restore"} {
+		if strings.HasPrefix(op, p) {
+			return false
+		}
+	}
+	return true
+}
+
+type auditContextKey int
+
+const (
+	auditPresignKey auditContextKey = iota
+	auditDisabledKey
+)
+
+func withPresignAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditPresignKey, true)
+}
+
+func withoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditDisabledKey, true)
+}
+
+func auditPrincipal(cfg AuditConfig, creds aws.CredentialsProvider) func(context.Context) string {
+	if cfg.Principal != nil {
+		return cfg.Principal
+	}
+	return func(ctx context.Context) string {
+		c, err := creds.Retrieve(ctx)
+		if err != nil {
+			return ""
+		}
+		return c.AccessKeyID
+	}
+}
+
+// auditCachedPresign records a presigned URL served from the presign
+// cache, which never reaches the audit middleware.
+func (c *Client) auditCachedPresign(ctx context.Context, op, bucket, key string) {
+	if c.cfg.Audit == nil || c.cfg.Audit.Sink == nil {
+		return
+	}
+	c.cfg.Audit.Sink.Record(AuditEvent{
+		Time:      time.Now().UTC(),
+		Principal: auditPrincipal(*c.cfg.Audit, c.creds.cache)(ctx),
+		Operation: "Presign" + op,
+		Bucket:    bucket,
+		Key:       key,
+		Outcome:   "success",
+	})
+}
+
+func newAuditMiddleware(cfg AuditConfig, creds aws.CredentialsProvider) func(*middleware.Stack) error {
+	principal := auditPrincipal(cfg, creds)
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("s3client.Audit",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				op := middleware.GetOperationName(ctx)
+				presign, _ := ctx.Value(auditPresignKey).(bool)
+				if disabled, _ := ctx.Value(auditDisabledKey).(bool); disabled || (!presign && auditSkipped(op)) {
+					return next.HandleInitialize(ctx, in)
+				}
+				start := time.Now()
+				out, md, err := next.HandleInitialize(ctx, in)
+				event := AuditEvent{
+					Time:      start.UTC(),
+					Principal: principal(ctx),
+					Operation: op,
+					Bucket:    inputField(in.Parameters, "Bucket"),
+					Key:       inputField(in.Parameters, "Key"),
+					Outcome:   "success",
+					Duration:  time.Since(start),
+				}
+				if presign {
+					event.Operation = "Presign" + op
+				}
+				if input, ok := in.Parameters.(*s3.DeleteObjectsInput); ok && input.Delete != nil {
+					for _, obj := range input.Delete.Objects {
+						event.Keys = append(event.Keys, aws.ToString(obj.Key))
+					}
+				}
+				if err != nil {
+					event.Outcome = "error"
+					event.Error = err.Error()
+				}
+				cfg.Sink.Record(event)
+				return out, md, err
+			}), middleware.After)
+	}
+}
+
+// NewAuditWriter returns a sink that writes events to w as JSON lines.
+func NewAuditWriter(w io.Writer) AuditSink {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return AuditFunc(func(e AuditEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(e)
+	})
+}
+
+// BucketAuditSink buffers events and stores them as JSON-lines objects
+// under prefix, one per flush. Its own writes are not audited.
+type BucketAuditSink struct {
+	c      *Client
+	bucket string
+	prefix string
+
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewBucketAuditSink flushes every interval and when c is closed. It
+// defaults to one minute.
+func NewBucketAuditSink(c *Client, bucket, prefix string, interval time.Duration) *BucketAuditSink {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	s := &BucketAuditSink{
+		c:      c,
+		bucket: bucket,
+		prefix: prefix,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = s.Flush(context.Background())
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	c.onClose(func() { _ = s.Close() })
+	return s
+}
+
+func (s *BucketAuditSink) Record(e AuditEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = json.NewEncoder(&s.buf).Encode(e)
+}
+
+func (s *BucketAuditSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	if s.buf.Len() == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	data := bytes.Clone(s.buf.Bytes())
+	s.buf.Reset()
+	s.mu.Unlock()
+	key := s.prefix + time.Now().UTC().Format("2006/01/02/150405.000000000") + ".jsonl"
+	// Flushes go through after Shutdown, which closes the sink last.
+	return s.c.PutObjectBytes(bypassShutdown(withoutAudit(ctx)), s.bucket, key, data, "application/x-ndjson", WithCompression(CompressionNone))
+}
+
+// Close stops the flush loop and writes any buffered events.
+func (s *BucketAuditSink) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+		err = s.Flush(context.Background())
+	})
+	return err
+}
@@ -0,0 +1,233 @@
+package s3client
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// BackupManifestName is the last entry of a backup archive. Object data is
+// stored below backupObjectsDir so keys cannot collide with it.
+const (
+	BackupManifestName = "manifest.json"
+	backupObjectsDir   = "objects/"
+	backupPAXRecord    = "S3CLIENT.object"
+)
+
+// BackupEntry describes one object in a backup archive. Key is relative to
+// the backed-up prefix.
+type BackupEntry struct {
+	Key             string            `json:"key"`
+	Size            int64             `json:"size"`
+	ETag            string            `json:"etag,omitempty"`
+	LastModified    time.Time         `json:"last_modified"`
+	ContentType     string            `json:"content_type,omitempty"`
+	ContentEncoding string            `json:"content_encoding,omitempty"`
+	CacheControl    string            `json:"cache_control,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+}
+
+type BackupManifest struct {
+	Bucket  string        `json:"bucket"`
+	Prefix  string        `json:"prefix"`
+	Created time.Time     `json:"created"`
+	Objects []BackupEntry `json:"objects"`
+}
+
+// BackupBucket streams every object under prefix into a tar archive written
+// to w, as stored and without decompression. Each entry carries its
+// BackupEntry as a PAX record, and the archive ends with a BackupManifest
+// listing all of them. Tags are skipped on backends without tagging.
+func (c *Client) BackupBucket(ctx context.Context, bucket, prefix string, w io.Writer) (BackupManifest, error) {
+	manifest := BackupManifest{Bucket: bucket, Prefix: prefix, Created: time.Now().UTC(), Objects: []BackupEntry{}}
+	tw := tar.NewWriter(w)
+	tagging := true
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		key := aws.ToString(obj.Key)
+		rel := strings.TrimPrefix(key, prefix)
+		if rel == "" || strings.HasSuffix(rel, "/") {
+			return nil
+		}
+		var tags map[string]string
+		if tagging {
+			var err error
+			tags, err = c.GetObjectTags(ctx, bucket, key)
+			if isNotImplemented(err) {
+				tagging = false
+			} else if err != nil {
+				return err
+			}
+		}
+		entry, err := c.writeBackupEntry(ctx, tw, bucket, key, rel, tags)
+		if err != nil {
+			return err
+		}
+		manifest.Objects = append(manifest.Objects, entry)
+		return nil
+	})
+	if err != nil {
+		return manifest, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	hdr := &tar.Header{
+		Name:     BackupManifestName,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  manifest.Created,
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return manifest, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return manifest, err
+	}
+	return manifest, tw.Close()
+}
+
+func (c *Client) writeBackupEntry(ctx context.Context, tw *tar.Writer, bucket, key, rel string, tags map[string]string) (BackupEntry, error) {
+	output, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return BackupEntry{}, err
+	}
+	defer output.Body.Close()
+
+	entry := backupEntry(rel, output, tags)
+	record, err := json.Marshal(entry)
+	if err != nil {
+		return BackupEntry{}, err
+	}
+	hdr := &tar.Header{
+		Name:       backupObjectsDir + rel,
+		Mode:       0o644,
+		Size:       entry.Size,
+		ModTime:    entry.LastModified,
+		Typeflag:   tar.TypeReg,
+		Format:     tar.FormatPAX,
+		PAXRecords: map[string]string{backupPAXRecord: string(record)},
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return BackupEntry{}, err
+	}
+	_, err = io.Copy(tw, output.Body)
+	return entry, err
+}
+
+func backupEntry(rel string, output *s3.GetObjectOutput, tags map[string]string) BackupEntry {
+	entry := BackupEntry{
+		Key:             rel,
+		Size:            aws.ToInt64(output.ContentLength),
+		ETag:            strings.Trim(aws.ToString(output.ETag), `"`),
+		LastModified:    aws.ToTime(output.LastModified),
+		ContentType:     aws.ToString(output.ContentType),
+		ContentEncoding: aws.ToString(output.ContentEncoding),
+		CacheControl:    aws.ToString(output.CacheControl),
+		Metadata:        output.Metadata,
+	}
+	if len(tags) > 0 {
+		entry.Tags = tags
+	}
+	return entry
+}
+
+// RestoreBucket replays an archive written by BackupBucket into bucket,
+// storing each object under prefix with its original content headers,
+// metadata and tags. It returns the number of objects restored, and fails
+// if the archive ends before every object in its manifest was seen.
+func (c *Client) RestoreBucket(ctx context.Context, r io.Reader, bucket, prefix string) (int64, error) {
+	tr := tar.NewReader(r)
+	var (
+		restored int64
+		seen     = map[string]bool{}
+		manifest *BackupManifest
+	)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return restored, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Name == BackupManifestName {
+			manifest = &BackupManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return restored, fmt.Errorf("s3client: malformed backup manifest: %w", err)
+			}
+			continue
+		}
+		rel, ok := strings.CutPrefix(hdr.Name, backupObjectsDir)
+		if !ok || rel == "" {
+			continue
+		}
+		entry := BackupEntry{Key: rel}
+		if record, ok := hdr.PAXRecords[backupPAXRecord]; ok {
+			if err := json.Unmarshal([]byte(record), &entry); err != nil {
+				return restored, fmt.Errorf("s3client: malformed backup entry %s: %w", hdr.Name, err)
+			}
+		}
+		if err := c.restoreEntry(ctx, tr, hdr.Size, bucket, prefix, entry); err != nil {
+			return restored, err
+		}
+		seen[entry.Key] = true
+		restored++
+	}
+	if manifest == nil {
+		return restored, errors.New("s3client: backup archive has no manifest")
+	}
+	for _, entry := range manifest.Objects {
+		if !seen[entry.Key] {
+			return restored, fmt.Errorf("s3client: backup archive is missing %s", entry.Key)
+		}
+	}
+	return restored, nil
+}
+
+func (c *Client) restoreEntry(ctx context.Context, body io.Reader, size int64, bucket, prefix string, entry BackupEntry) error {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(prefix + entry.Key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		Metadata:      entry.Metadata,
+	}
+	if entry.ContentType != "" {
+		input.ContentType = aws.String(entry.ContentType)
+	}
+	if entry.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(entry.ContentEncoding)
+	}
+	if entry.CacheControl != "" {
+		input.CacheControl = aws.String(entry.CacheControl)
+	}
+	if len(entry.Tags) > 0 {
+		tags := url.Values{}
+		for k, v := range entry.Tags {
+			tags.Set(k, v)
+		}
+		input.Tagging = aws.String(tags.Encode())
+	}
+	_, err := c.upload(ctx, input)
+	return err
+}
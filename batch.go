@@ -0,0 +1,208 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type UploadItem struct {
+	Key         string
+	Data        []byte
+	ContentType string
+}
+
+// UploadMany puts items with up to concurrency requests in flight. It
+// attempts every item and returns the failures joined into one error.
+func (c *Client) UploadMany(ctx context.Context, bucket string, items []UploadItem, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = 16
+	}
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, concurrency)
+	)
+	for _, item := range items {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(item UploadItem) {
+			defer func() { <-sem; wg.Done() }()
+			err := c.retryThrottled(ctx, func() error {
+				return c.PutObjectBytes(ctx, bucket, item.Key, item.Data, item.ContentType)
+			})
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", item.Key, err))
+				mu.Unlock()
+			}
+		}(item)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+const (
+	packSuffix  = ".pack"
+	indexSuffix = ".idx"
+)
+
+type PackEntry struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+}
+
+type packIndex struct {
+	Pack    string      `json:"pack"`
+	Entries []PackEntry `json:"entries"`
+}
+
+// PackWriter aggregates small payloads into pack objects of roughly
+// targetSize bytes under prefix. Each pack is followed by a JSON index
+// object so a PackReader can resolve entries with ranged reads.
+type PackWriter struct {
+	c          *Client
+	bucket     string
+	prefix     string
+	targetSize int
+
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	index []PackEntry
+	seq   int
+}
+
+func (c *Client) NewPackWriter(bucket, prefix string, targetSize int) *PackWriter {
+	if targetSize <= 0 {
+		targetSize = 64 << 20
+	}
+	return &PackWriter{c: c, bucket: bucket, prefix: prefix, targetSize: targetSize}
+}
+
+func (w *PackWriter) Add(ctx context.Context, name string, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.index = append(w.index, PackEntry{Name: name, Offset: int64(w.buf.Len()), Size: int64(len(data))})
+	w.buf.Write(data)
+	if w.buf.Len() >= w.targetSize {
+		return w.flush(ctx)
+	}
+	return nil
+}
+
+func (w *PackWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush(ctx)
+}
+
+func (w *PackWriter) flush(ctx context.Context) error {
+	if len(w.index) == 0 {
+		return nil
+	}
+	w.seq++
+	base := fmt.Sprintf("%s%s-%06d", w.prefix, time.Now().UTC().Format("20060102T150405.000000000"), w.seq)
+	pack := base + packSuffix
+	if err := w.c.PutObjectBytes(ctx, w.bucket, pack, w.buf.Bytes(), "application/octet-stream", WithCompression(CompressionNone)); err != nil {
+		return err
+	}
+	idx, err := json.Marshal(packIndex{Pack: pack, Entries: w.index})
+	if err != nil {
+		return err
+	}
+	if err := w.c.PutObjectBytes(ctx, w.bucket, base+indexSuffix, idx, "application/json", WithCompression(CompressionNone)); err != nil {
+		return err
+	}
+	w.buf.Reset()
+	w.index = nil
+	return nil
+}
+
+func (w *PackWriter) Close(ctx context.Context) error {
+	return w.Flush(ctx)
+}
+
+type packLocation struct {
+	pack string
+	PackEntry
+}
+
+type PackReader struct {
+	c       *Client
+	bucket  string
+	entries map[string]packLocation
+}
+
+// OpenPacks loads every pack index under prefix. When the same name was
+// packed more than once, the most recent pack wins.
+func (c *Client) OpenPacks(ctx context.Context, bucket, prefix string) (*PackReader, error) {
+	var indexes []string
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		if strings.HasSuffix(*obj.Key, indexSuffix) {
+			indexes = append(indexes, *obj.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(indexes)
+
+	r := &PackReader{c: c, bucket: bucket, entries: map[string]packLocation{}}
+	for _, key := range indexes {
+		data, err := c.GetObjectBytes(ctx, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		var idx packIndex
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return nil, fmt.Errorf("s3client: invalid pack index %s: %w", key, err)
+		}
+		for _, e := range idx.Entries {
+			r.entries[e.Name] = packLocation{pack: idx.Pack, PackEntry: e}
+		}
+	}
+	return r, nil
+}
+
+func (r *PackReader) Names() []string {
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *PackReader) Get(ctx context.Context, name string) ([]byte, error) {
+	loc, ok := r.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("s3client: %q not found in packs", name)
+	}
+	if loc.Size == 0 {
+		return []byte{}, nil
+	}
+	output, err := r.c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(loc.pack),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", loc.Offset, loc.Offset+loc.Size-1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
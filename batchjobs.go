@@ -0,0 +1,304 @@
+package s3client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	s3ctltypes "github.com/aws/aws-sdk-go-v2/service/s3control/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	smithyendpoints "github.com/aws/smithy-go/endpoints"
+)
+
+// WriteBatchManifest lists the objects under prefix into a CSV manifest at
+// manifestBucket/manifestKey in the format S3 Batch Operations reads, and
+// returns its ETag and the number of objects listed.
+func (c *Client) WriteBatchManifest(ctx context.Context, bucket, prefix, manifestBucket, manifestKey string) (string, int64, error) {
+	w := c.NewCSVWriter(ctx, manifestBucket, manifestKey, WithCompression(CompressionNone))
+	var n int64
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		key := aws.ToString(obj.Key)
+		if strings.HasSuffix(key, "/") {
+			return nil
+		}
+		n++
+		return w.Write([]string{bucket, uriEncode(key)})
+	})
+	if err != nil {
+		w.CloseWithError(err)
+		return "", 0, err
+	}
+	if err := w.Close(); err != nil {
+		return "", 0, err
+	}
+	info, err := c.StatObject(ctx, manifestBucket, manifestKey)
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.Trim(info.ETag, `"`), n, nil
+}
+
+type BatchOptions struct {
+	// AccountID owns the jobs; it defaults to the caller's account.
+	AccountID string
+	// Endpoint overrides the S3 Control endpoint.
+	Endpoint string
+}
+
+// BatchOperations creates and monitors S3 Batch Operations jobs through
+// the S3 Control API. It is only available on AWS.
+type BatchOperations struct {
+	c      *Client
+	mu     sync.Mutex
+	opts   BatchOptions
+	client *s3control.Client
+}
+
+func (c *Client) BatchOperations(opts BatchOptions) *BatchOperations {
+	return &BatchOperations{c: c, opts: opts}
+}
+
+// BatchJob describes a job over a manifest written by WriteBatchManifest.
+// Exactly one of Copy, Tags, Restore and LambdaARN selects the operation.
+type BatchJob struct {
+	ManifestBucket string
+	ManifestKey    string
+	ManifestETag   string
+	// RoleARN is the IAM role the job runs as.
+	RoleARN     string
+	Priority    int32
+	Description string
+
+	Copy      *BatchCopy
+	Tags      map[string]string
+	Restore   *BatchRestore
+	LambdaARN string
+
+	// ReportBucket, when set, receives a completion report of the failed
+	// tasks under ReportPrefix.
+	ReportBucket string
+	ReportPrefix string
+}
+
+type BatchCopy struct {
+	Bucket       string
+	Prefix       string
+	StorageClass types.StorageClass
+}
+
+type BatchRestore struct {
+	Days int32
+	// Tier is "STANDARD" or "BULK"; it defaults to "BULK".
+	Tier string
+}
+
+type BatchJobStatus struct {
+	JobID     string
+	Status    string
+	Created   time.Time
+	Terminal  time.Time
+	Total     int64
+	Succeeded int64
+	Failed    int64
+	Reasons   []string
+}
+
+// Done reports whether the job reached a terminal state.
+func (s BatchJobStatus) Done() bool {
+	switch s.Status {
+	case "Complete", "Failed", "Cancelled":
+		return true
+	}
+	return false
+}
+
+// CreateJob submits job without requiring confirmation and returns its ID.
+func (b *BatchOperations) CreateJob(ctx context.Context, job BatchJob) (string, error) {
+	var op s3ctltypes.JobOperation
+	ops := 0
+	if job.Copy != nil {
+		ops++
+		op.S3PutObjectCopy = &s3ctltypes.S3CopyObjectOperation{
+			TargetResource: aws.String("arn:aws:s3:::" + job.Copy.Bucket),
+			StorageClass:   s3ctltypes.S3StorageClass(job.Copy.StorageClass),
+		}
+		if job.Copy.Prefix != "" {
+			op.S3PutObjectCopy.TargetKeyPrefix = aws.String(job.Copy.Prefix)
+		}
+	}
+	if len(job.Tags) > 0 {
+		ops++
+		op.S3PutObjectTagging = &s3ctltypes.S3SetObjectTaggingOperation{}
+		for k, v := range job.Tags {
+			op.S3PutObjectTagging.TagSet = append(op.S3PutObjectTagging.TagSet, s3ctltypes.S3Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+	}
+	if job.Restore != nil {
+		ops++
+		tier := s3ctltypes.S3GlacierJobTier(job.Restore.Tier)
+		if tier == "" {
+			tier = s3ctltypes.S3GlacierJobTierBulk
+		}
+		op.S3InitiateRestoreObject = &s3ctltypes.S3InitiateRestoreObjectOperation{
+			ExpirationInDays: aws.Int32(job.Restore.Days),
+			GlacierJobTier:   tier,
+		}
+	}
+	if job.LambdaARN != "" {
+		ops++
+		op.LambdaInvoke = &s3ctltypes.LambdaInvokeOperation{FunctionArn: aws.String(job.LambdaARN)}
+	}
+	if ops != 1 {
+		return "", errors.New("s3client: batch job needs exactly one operation")
+	}
+	report := &s3ctltypes.JobReport{}
+	if job.ReportBucket != "" {
+		report = &s3ctltypes.JobReport{
+			Enabled:     true,
+			Bucket:      aws.String("arn:aws:s3:::" + job.ReportBucket),
+			Format:      s3ctltypes.JobReportFormatReportCsv20180820,
+			Prefix:      aws.String(job.ReportPrefix),
+			ReportScope: s3ctltypes.JobReportScopeFailedTasksOnly,
+		}
+	}
+	input := &s3control.CreateJobInput{
+		ConfirmationRequired: aws.Bool(false),
+		Operation:            &op,
+		Report:               report,
+		ClientRequestToken:   aws.String(rand.Text()),
+		Manifest: &s3ctltypes.JobManifest{
+			Spec: &s3ctltypes.JobManifestSpec{
+				Format: s3ctltypes.JobManifestFormatS3BatchOperationsCsv20180820,
+				Fields: []s3ctltypes.JobManifestFieldName{s3ctltypes.JobManifestFieldNameBucket, s3ctltypes.JobManifestFieldNameKey},
+			},
+			Location: &s3ctltypes.JobManifestLocation{
+				ObjectArn: aws.String("arn:aws:s3:::" + job.ManifestBucket + "/" + job.ManifestKey),
+				ETag:      aws.String(job.ManifestETag),
+			},
+		},
+		Priority: aws.Int32(job.Priority),
+		RoleArn:  aws.String(job.RoleARN),
+	}
+	if job.Description != "" {
+		input.Description = aws.String(job.Description)
+	}
+	client, account, err := b.control(ctx)
+	if err != nil {
+		return "", err
+	}
+	input.AccountId = aws.String(account)
+	output, err := client.CreateJob(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.JobId), nil
+}
+
+func (b *BatchOperations) DescribeJob(ctx context.Context, jobID string) (BatchJobStatus, error) {
+	client, account, err := b.control(ctx)
+	if err != nil {
+		return BatchJobStatus{}, err
+	}
+	output, err := client.DescribeJob(ctx, &s3control.DescribeJobInput{
+		AccountId: aws.String(account),
+		JobId:     aws.String(jobID),
+	})
+	if err != nil {
+		return BatchJobStatus{}, err
+	}
+	job := output.Job
+	if job == nil {
+		return BatchJobStatus{JobID: jobID}, nil
+	}
+	status := BatchJobStatus{
+		JobID:    aws.ToString(job.JobId),
+		Status:   string(job.Status),
+		Created:  aws.ToTime(job.CreationTime),
+		Terminal: aws.ToTime(job.TerminationDate),
+	}
+	if p := job.ProgressSummary; p != nil {
+		status.Total = aws.ToInt64(p.TotalNumberOfTasks)
+		status.Succeeded = aws.ToInt64(p.NumberOfTasksSucceeded)
+		status.Failed = aws.ToInt64(p.NumberOfTasksFailed)
+	}
+	for _, f := range job.FailureReasons {
+		status.Reasons = append(status.Reasons, aws.ToString(f.FailureReason))
+	}
+	return status, nil
+}
+
+// WaitJob polls the job every interval, 30 seconds by default, until it
+// completes, fails or is cancelled.
+func (b *BatchOperations) WaitJob(ctx context.Context, jobID string, interval time.Duration) (BatchJobStatus, error) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	for {
+		status, err := b.DescribeJob(ctx, jobID)
+		if err != nil || status.Done() {
+			return status, err
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return status, ctx.Err()
+		}
+	}
+}
+
+// control returns the S3 Control client, which shares the credentials and
+// HTTP transport of the S3 client, and the account it acts for.
+func (b *BatchOperations) control(ctx context.Context) (*s3control.Client, string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.opts.AccountID == "" {
+		stsClient := sts.New(sts.Options{
+			Region:      b.c.cfg.Region,
+			Credentials: b.c.creds.cache,
+			HTTPClient:  b.c.s3Client.Options().HTTPClient,
+		})
+		output, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return nil, "", err
+		}
+		b.opts.AccountID = aws.ToString(output.Account)
+	}
+	if b.client == nil {
+		s3Opts := b.c.s3Client.Options()
+		b.client = s3control.New(s3control.Options{
+			Region:      b.c.cfg.Region,
+			Credentials: b.c.creds.cache,
+			HTTPClient:  s3Opts.HTTPClient,
+			Logger:      s3Opts.Logger,
+		}, func(o *s3control.Options) {
+			if b.opts.Endpoint != "" {
+				o.EndpointResolverV2 = fixedControlEndpoint(b.opts.Endpoint)
+			}
+			if len(b.c.cfg.DefaultHeaders) > 0 {
+				o.APIOptions = append(o.APIOptions, newDefaultHeaders(b.c.cfg.DefaultHeaders))
+			}
+		})
+	}
+	return b.client, b.opts.AccountID, nil
+}
+
+// fixedControlEndpoint sends every request to endpoint as is. The default
+// rules would prefix its host with the account ID, which a custom endpoint
+// rarely has a DNS entry for.
+type fixedControlEndpoint string
+
+func (e fixedControlEndpoint) ResolveEndpoint(ctx context.Context, params s3control.EndpointParameters) (smithyendpoints.Endpoint, error) {
+	u, err := url.Parse(string(e))
+	if err != nil {
+		return smithyendpoints.Endpoint{}, fmt.Errorf("s3client: invalid S3 Control endpoint: %w", err)
+	}
+	return smithyendpoints.Endpoint{URI: *u}, nil
+}
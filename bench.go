@@ -0,0 +1,161 @@
+package s3client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+type BenchmarkOptions struct {
+	// Sizes lists the object sizes to measure; it defaults to 1 MiB.
+	Sizes []int64
+	// Concurrency defaults to 8 workers.
+	Concurrency int
+	// Duration of each PUT and GET phase; it defaults to 10 seconds.
+	Duration time.Duration
+	// Prefix holds the benchmark objects, which are deleted afterwards. It
+	// defaults to "s3client-benchmark/".
+	Prefix string
+}
+
+type BenchmarkResult struct {
+	Size int64
+	Put  BenchmarkOpResult
+	Get  BenchmarkOpResult
+}
+
+type BenchmarkOpResult struct {
+	Operations int64
+	Errors     int64
+	Bytes      int64
+	Duration   time.Duration
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// Throughput returns bytes per second.
+func (r BenchmarkOpResult) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / r.Duration.Seconds()
+}
+
+func (r BenchmarkOpResult) OpsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Operations) / r.Duration.Seconds()
+}
+
+// Benchmark measures PUT and then GET throughput and latency for every
+// size in opts against bucket. Objects are uploaded uncompressed.
+func (c *Client) Benchmark(ctx context.Context, bucket string, opts BenchmarkOptions) ([]BenchmarkResult, error) {
+	if len(opts.Sizes) == 0 {
+		opts.Sizes = []int64{1 << 20}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = 10 * time.Second
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "s3client-benchmark/"
+	}
+
+	var results []BenchmarkResult
+	for _, size := range opts.Sizes {
+		prefix := fmt.Sprintf("%s%d/", opts.Prefix, size)
+		data := make([]byte, size)
+		rand.Read(data)
+
+		var (
+			mu   sync.Mutex
+			keys []string
+		)
+		put := benchmarkPhase(ctx, opts.Concurrency, opts.Duration, func(ctx context.Context, worker, n int) (int64, error) {
+			key := fmt.Sprintf("%s%d-%d", prefix, worker, n)
+			if err := c.PutObjectBytes(ctx, bucket, key, data, "application/octet-stream", WithCompression(CompressionNone)); err != nil {
+				return 0, err
+			}
+			mu.Lock()
+			keys = append(keys, key)
+			mu.Unlock()
+			return size, nil
+		})
+		result := BenchmarkResult{Size: size, Put: put}
+		if len(keys) > 0 {
+			result.Get = benchmarkPhase(ctx, opts.Concurrency, opts.Duration, func(ctx context.Context, _, _ int) (int64, error) {
+				body, err := c.GetObject(ctx, bucket, keys[mathrand.IntN(len(keys))], WithDecompression(false))
+				if err != nil {
+					return 0, err
+				}
+				defer body.Close()
+				return io.Copy(io.Discard, body)
+			})
+		}
+		results = append(results, result)
+
+		_, err := c.DeletePrefix(context.WithoutCancel(ctx), bucket, prefix, ListOptions{})
+		if err := errors.Join(ctx.Err(), err); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// benchmarkPhase runs op from concurrency workers until d elapses and
+// summarizes the successful calls. op gets the phase context, which ends
+// the call in progress when d elapses.
+func benchmarkPhase(ctx context.Context, concurrency int, d time.Duration, op func(ctx context.Context, worker, n int) (int64, error)) BenchmarkOpResult {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	var (
+		mu        sync.Mutex
+		result    BenchmarkOpResult
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; ctx.Err() == nil; n++ {
+				t := time.Now()
+				bytes, err := op(ctx, w, n)
+				elapsed := time.Since(t)
+				mu.Lock()
+				if err != nil {
+					// Calls cut short by the end of the phase are not errors.
+					if ctx.Err() == nil {
+						result.Errors++
+					}
+				} else {
+					result.Operations++
+					result.Bytes += bytes
+					latencies = append(latencies, elapsed)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.Duration = time.Since(start)
+
+	slices.Sort(latencies)
+	if n := len(latencies); n > 0 {
+		pct := func(p int) time.Duration { return latencies[(n-1)*p/100] }
+		result.P50, result.P90, result.P99, result.Max = pct(50), pct(90), pct(99), latencies[n-1]
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+var ErrCircuitOpen = errors.New("s3client: circuit breaker is open")
+
+// CircuitBreakerConfig makes the client fail fast with ErrCircuitOpen after
+// FailureThreshold consecutive backend failures (network errors and 5xx
+// responses). After CoolDown, up to HalfOpenProbes requests are let through;
+// one success closes the circuit again, a failure reopens it.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	CoolDown         time.Duration
+	HalfOpenProbes   int
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = 30 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	return &circuitBreaker{cfg: cfg}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cfg.CoolDown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probes = 0
+		fallthrough
+	case breakerHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// abandon gives back the probe slot of a call canceled by its caller,
+// which says nothing about the backend.
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func backendFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	status := httpStatus(err)
+	return status == 0 || status >= 500
+}
+
+func (b *circuitBreaker) addMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("s3client.CircuitBreaker",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if !b.allow() {
+				return middleware.InitializeOutput{}, middleware.Metadata{}, ErrCircuitOpen
+			}
+			out, md, err := next.HandleInitialize(ctx, in)
+			if ctx.Err() != nil {
+				b.abandon()
+			} else {
+				b.record(backendFailure(err))
+			}
+			return out, md, err
+		}), middleware.After)
+}
@@ -0,0 +1,114 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Bucket binds object operations to a single bucket.
+type Bucket struct {
+	c    *Client
+	name string
+}
+
+func (c *Client) Bucket(name string) *Bucket {
+	return &Bucket{c: c, name: name}
+}
+
+// DefaultBucket returns a handle for Config.DefaultBucket.
+func (c *Client) DefaultBucket() *Bucket {
+	return c.Bucket(c.cfg.DefaultBucket)
+}
+
+func (b *Bucket) Name() string {
+	return b.name
+}
+
+func (b *Bucket) Client() *Client {
+	return b.c
+}
+
+func (b *Bucket) Exists(ctx context.Context) (bool, error) {
+	return b.c.BucketExists(ctx, b.name)
+}
+
+func (b *Bucket) Put(ctx context.Context, key string, body io.Reader, contentType string, opts ...CallOption) error {
+	return b.c.PutObject(ctx, b.name, key, body, contentType, opts...)
+}
+
+func (b *Bucket) PutBytes(ctx context.Context, key string, data []byte, contentType string, opts ...CallOption) error {
+	return b.c.PutObjectBytes(ctx, b.name, key, data, contentType, opts...)
+}
+
+func (b *Bucket) Get(ctx context.Context, key string, opts ...CallOption) (io.ReadCloser, error) {
+	return b.c.GetObject(ctx, b.name, key, opts...)
+}
+
+func (b *Bucket) GetBytes(ctx context.Context, key string, opts ...CallOption) ([]byte, error) {
+	return b.c.GetObjectBytes(ctx, b.name, key, opts...)
+}
+
+func (b *Bucket) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	return b.c.StatObject(ctx, b.name, key)
+}
+
+func (b *Bucket) ObjectExists(ctx context.Context, key string) (bool, error) {
+	return b.c.ObjectExists(ctx, b.name, key)
+}
+
+func (b *Bucket) Delete(ctx context.Context, key string) error {
+	return b.c.DeleteObject(ctx, b.name, key)
+}
+
+func (b *Bucket) DeleteMany(ctx context.Context, keys []string) error {
+	return b.c.DeleteObjects(ctx, b.name, keys)
+}
+
+func (b *Bucket) DeletePrefix(ctx context.Context, prefix string, opts ListOptions) (int64, error) {
+	return b.c.DeletePrefix(ctx, b.name, prefix, opts)
+}
+
+func (b *Bucket) List(ctx context.Context, prefix string) ([]string, error) {
+	return b.c.ListObjects(ctx, b.name, prefix)
+}
+
+func (b *Bucket) ListDetailed(ctx context.Context, prefix string, opts ListOptions) ([]ObjectInfo, error) {
+	return b.c.ListObjectsDetailed(ctx, b.name, prefix, opts)
+}
+
+func (b *Bucket) ListDir(ctx context.Context, prefix, delimiter string) (DirListing, error) {
+	return b.c.ListDir(ctx, b.name, prefix, delimiter)
+}
+
+func (b *Bucket) Walk(ctx context.Context, prefix string, fn WalkFunc) error {
+	return b.c.Walk(ctx, b.name, prefix, fn)
+}
+
+func (b *Bucket) WalkDetailed(ctx context.Context, prefix string, prefetch int, fn WalkFunc) error {
+	return b.c.WalkDetailed(ctx, b.name, prefix, prefetch, fn)
+}
+
+func (b *Bucket) Upload(ctx context.Context, key, localPath string, opts ...CallOption) error {
+	return b.c.UploadFile(ctx, b.name, key, localPath, opts...)
+}
+
+func (b *Bucket) Download(ctx context.Context, key, localPath string, opts ...CallOption) error {
+	return b.c.DownloadFile(ctx, b.name, key, localPath, opts...)
+}
+
+func (b *Bucket) Copy(ctx context.Context, srcKey, dstKey string) error {
+	return b.c.CopyObject(ctx, b.name, srcKey, b.name, dstKey)
+}
+
+func (b *Bucket) Move(ctx context.Context, srcKey, dstKey string) error {
+	return b.c.MoveObject(ctx, b.name, srcKey, dstKey)
+}
+
+func (b *Bucket) PresignGet(ctx context.Context, key string, expiry time.Duration, opts ...CallOption) (string, error) {
+	return b.c.PresignGetObject(ctx, b.name, key, expiry, opts...)
+}
+
+func (b *Bucket) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return b.c.PresignPutObject(ctx, b.name, key, expiry)
+}
@@ -0,0 +1,124 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// BucketInfo summarizes a bucket's configuration. Zero fields mean the
+// setting is off or was not reported.
+type BucketInfo struct {
+	Name         string
+	Region       string
+	CreationDate time.Time
+	Versioning   types.BucketVersioningStatus
+	MFADelete    bool
+	Encryption   types.ServerSideEncryption
+	KMSKeyID     string
+	ObjectLock   bool
+	// LockMode and LockDays or LockYears are the default retention.
+	LockMode  types.ObjectLockRetentionMode
+	LockDays  int32
+	LockYears int32
+	// Unsupported names the queries the backend answered with
+	// NotImplemented.
+	Unsupported []string
+}
+
+// GetBucketInfo gathers the region, creation date, versioning, default
+// encryption and Object Lock settings of bucket. The creation date needs
+// s3:ListAllMyBuckets and is left zero without it.
+func (c *Client) GetBucketInfo(ctx context.Context, bucket string) (BucketInfo, error) {
+	info := BucketInfo{Name: bucket}
+	// tolerate reports whether err is absorbed into info.
+	tolerate := func(op string, err error, missing ...string) bool {
+		if isNotImplemented(err) {
+			info.Unsupported = append(info.Unsupported, op)
+			return true
+		}
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			for _, code := range missing {
+				if apiErr.ErrorCode() == code {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	head, err := c.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return BucketInfo{}, err
+	}
+	info.Region = aws.ToString(head.BucketRegion)
+	if info.Region == "" {
+		location, err := c.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+		switch {
+		case err == nil:
+			info.Region = string(location.LocationConstraint)
+			if info.Region == "" {
+				info.Region = "us-east-1"
+			}
+		case !tolerate("GetBucketLocation", err):
+			return BucketInfo{}, err
+		}
+	}
+
+	buckets, err := c.ListBucketsDetailed(ctx, ListBucketsOptions{Prefix: bucket})
+	switch {
+	case err == nil:
+		for _, b := range buckets {
+			if b.Name == bucket {
+				info.CreationDate = b.CreationDate
+			}
+		}
+	case !isForbidden(err) && !tolerate("ListBuckets", err):
+		return BucketInfo{}, err
+	}
+
+	versioning, err := c.s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String(bucket)})
+	switch {
+	case err == nil:
+		info.Versioning = versioning.Status
+		info.MFADelete = versioning.MFADelete == types.MFADeleteStatusEnabled
+	case !tolerate("GetBucketVersioning", err):
+		return BucketInfo{}, err
+	}
+
+	encryption, err := c.s3Client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: aws.String(bucket)})
+	switch {
+	case err == nil && encryption.ServerSideEncryptionConfiguration != nil:
+		for _, rule := range encryption.ServerSideEncryptionConfiguration.Rules {
+			if d := rule.ApplyServerSideEncryptionByDefault; d != nil {
+				info.Encryption = d.SSEAlgorithm
+				info.KMSKeyID = aws.ToString(d.KMSMasterKeyID)
+				break
+			}
+		}
+	case err != nil && !tolerate("GetBucketEncryption", err, "ServerSideEncryptionConfigurationNotFoundError"):
+		return BucketInfo{}, err
+	}
+
+	lock, err := c.s3Client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: aws.String(bucket)})
+	switch {
+	case err == nil && lock.ObjectLockConfiguration != nil:
+		cfg := lock.ObjectLockConfiguration
+		info.ObjectLock = cfg.ObjectLockEnabled == types.ObjectLockEnabledEnabled
+		if cfg.Rule != nil && cfg.Rule.DefaultRetention != nil {
+			r := cfg.Rule.DefaultRetention
+			info.LockMode = r.Mode
+			info.LockDays = aws.ToInt32(r.Days)
+			info.LockYears = aws.ToInt32(r.Years)
+		}
+	case err != nil && !tolerate("GetObjectLockConfiguration", err, "ObjectLockConfigurationNotFoundError"):
+		return BucketInfo{}, err
+	}
+	return info, nil
+}
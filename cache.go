@@ -0,0 +1,214 @@
+package s3client
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// CacheConfig enables an in-memory LRU cache in front of GetObjectBytes and
+// StatObject. Entries younger than TTL are served without a request; older
+// ones are revalidated with a conditional GET on their ETag.
+type CacheConfig struct {
+	MaxEntries    int
+	MaxBytes      int64
+	TTL           time.Duration
+	MaxObjectSize int64
+}
+
+type objectCache struct {
+	cfg   CacheConfig
+	mu    sync.Mutex
+	lru   *list.List
+	items map[string]*list.Element
+	bytes int64
+}
+
+type cacheEntry struct {
+	id      string
+	info    ObjectInfo
+	data    []byte
+	hasData bool
+	fetched time.Time
+}
+
+func newObjectCache(cfg CacheConfig) *objectCache {
+	return &objectCache{
+		cfg:   cfg,
+		lru:   list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func cacheID(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+func (oc *objectCache) fresh(e *cacheEntry) bool {
+	return oc.cfg.TTL > 0 && time.Since(e.fetched) < oc.cfg.TTL
+}
+
+func (oc *objectCache) lookup(bucket, key string) (*cacheEntry, bool) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	el, ok := oc.items[cacheID(bucket, key)]
+	if !ok {
+		return nil, false
+	}
+	oc.lru.MoveToFront(el)
+	e := *el.Value.(*cacheEntry)
+	return &e, true
+}
+
+func (oc *objectCache) stat(bucket, key string) (ObjectInfo, bool) {
+	e, ok := oc.lookup(bucket, key)
+	if !ok || !oc.fresh(e) {
+		return ObjectInfo{}, false
+	}
+	return e.info, true
+}
+
+func (oc *objectCache) putStat(bucket, key string, info ObjectInfo) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if el, ok := oc.items[cacheID(bucket, key)]; ok {
+		e := el.Value.(*cacheEntry)
+		if e.info.ETag == info.ETag {
+			e.info = info
+			e.fetched = time.Now()
+			oc.lru.MoveToFront(el)
+			return
+		}
+	}
+	oc.store(&cacheEntry{id: cacheID(bucket, key), info: info, fetched: time.Now()})
+}
+
+func (oc *objectCache) putData(bucket, key string, info ObjectInfo, data []byte) {
+	if oc.cfg.MaxObjectSize > 0 && int64(len(data)) > oc.cfg.MaxObjectSize {
+		oc.invalidate(bucket, key)
+		return
+	}
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.store(&cacheEntry{id: cacheID(bucket, key), info: info, data: data, hasData: true, fetched: time.Now()})
+}
+
+func (oc *objectCache) touch(bucket, key string) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if el, ok := oc.items[cacheID(bucket, key)]; ok {
+		el.Value.(*cacheEntry).fetched = time.Now()
+		oc.lru.MoveToFront(el)
+	}
+}
+
+func (oc *objectCache) invalidate(bucket, key string) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if el, ok := oc.items[cacheID(bucket, key)]; ok {
+		oc.remove(el)
+	}
+}
+
+func (oc *objectCache) clear() {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.lru.Init()
+	oc.items = map[string]*list.Element{}
+	oc.bytes = 0
+}
+
+func (oc *objectCache) store(e *cacheEntry) {
+	if el, ok := oc.items[e.id]; ok {
+		oc.remove(el)
+	}
+	oc.items[e.id] = oc.lru.PushFront(e)
+	oc.bytes += int64(len(e.data))
+	for oc.lru.Len() > 1 && oc.overLimit() {
+		oc.remove(oc.lru.Back())
+	}
+}
+
+func (oc *objectCache) overLimit() bool {
+	return (oc.cfg.MaxEntries > 0 && oc.lru.Len() > oc.cfg.MaxEntries) ||
+		(oc.cfg.MaxBytes > 0 && oc.bytes > oc.cfg.MaxBytes)
+}
+
+func (oc *objectCache) remove(el *list.Element) {
+	e := oc.lru.Remove(el).(*cacheEntry)
+	delete(oc.items, e.id)
+	oc.bytes -= int64(len(e.data))
+}
+
+func (c *Client) cachedObjectBytes(ctx context.Context, bucket, key string, o callOptions) ([]byte, error) {
+	e, ok := c.cache.lookup(bucket, key)
+	if ok && e.hasData && c.cache.fresh(e) {
+		return cachedBytes(bucket, key, e.data, e.info, o)
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if ok && e.hasData && e.info.ETag != "" {
+		input.IfNoneMatch = aws.String(e.info.ETag)
+	}
+	output, err := c.s3Client.GetObject(ctx, input)
+	if err != nil {
+		if input.IfNoneMatch != nil && isNotModified(err) {
+			c.cache.touch(bucket, key)
+			return cachedBytes(bucket, key, e.data, e.info, o)
+		}
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	info := getObjectInfo(key, output)
+	if err := o.checkInMemorySize(bucket, key, info); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.putData(bucket, key, info, data)
+	return cachedBytes(bucket, key, data, info, o)
+}
+
+// cachedBytes returns a private copy of raw cached bytes, decoding them
+// when the call asks for decompression. The call's in-memory limit applies
+// as it does to objects fetched from the server.
+func cachedBytes(bucket, key string, data []byte, info ObjectInfo, o callOptions) ([]byte, error) {
+	if err := o.checkInMemorySize(bucket, key, info); err != nil {
+		return nil, err
+	}
+	if o.decompress && isCompressed(info.ContentEncoding) {
+		body, err := decompressBody(io.NopCloser(bytes.NewReader(data)), info.ContentEncoding)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return o.readAll(bucket, key, body)
+	}
+	return bytes.Clone(data), nil
+}
+
+func isNotModified(err error) bool {
+	var respErr *awshttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotModified
+}
+
+func (c *Client) invalidate(bucket, key string) {
+	if c.cache != nil {
+		c.cache.invalidate(bucket, key)
+	}
+}
@@ -0,0 +1,57 @@
+package s3client
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// CacheControlRule maps a content type to a Cache-Control value.
+// ContentType is an exact media type such as "text/html", a type wildcard
+// such as "image/*", or "*" for everything else.
+type CacheControlRule struct {
+	ContentType  string
+	CacheControl string
+}
+
+// CacheControlPolicy is applied in order; the first matching rule wins.
+type CacheControlPolicy []CacheControlRule
+
+// StaticSiteCacheControl caches fingerprinted assets for a year and makes
+// browsers revalidate HTML.
+var StaticSiteCacheControl = CacheControlPolicy{
+	{ContentType: "text/html", CacheControl: "no-cache"},
+	{ContentType: "image/*", CacheControl: "public, max-age=31536000, immutable"},
+	{ContentType: "font/*", CacheControl: "public, max-age=31536000, immutable"},
+	{ContentType: "text/css", CacheControl: "public, max-age=31536000, immutable"},
+	{ContentType: "text/javascript", CacheControl: "public, max-age=31536000, immutable"},
+	{ContentType: "application/javascript", CacheControl: "public, max-age=31536000, immutable"},
+}
+
+// For returns the Cache-Control value for contentType, or "" when no rule
+// matches.
+func (p CacheControlPolicy) For(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, rule := range p {
+		pattern := strings.ToLower(rule.ContentType)
+		if pattern == "*" || pattern == mediaType || pattern == major+"/*" {
+			return rule.CacheControl
+		}
+	}
+	return ""
+}
+
+func (c *Client) applyCacheControl(input *s3.PutObjectInput) {
+	if input.CacheControl != nil {
+		return
+	}
+	if cc := c.cfg.CacheControl.For(aws.ToString(input.ContentType)); cc != "" {
+		input.CacheControl = aws.String(cc)
+	}
+}
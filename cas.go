@@ -0,0 +1,37 @@
+package s3client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const defaultCASPrefix = "cas/"
+
+// CASKey returns the key PutObjectCAS stores a blob with the given hex
+// digest under.
+func (c *Client) CASKey(digest string) string {
+	prefix := c.cfg.CASPrefix
+	if prefix == "" {
+		prefix = defaultCASPrefix
+	}
+	return prefix + digest
+}
+
+// PutObjectCAS stores data under its SHA-256 digest and returns the digest.
+// The upload is skipped when an object with that key already exists.
+func (c *Client) PutObjectCAS(ctx context.Context, bucket string, data []byte, opts ...CallOption) (string, error) {
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	key := c.CASKey(digest)
+	exists, err := c.ObjectExists(ctx, bucket, key)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if err := c.PutObjectBytes(ctx, bucket, key, data, "application/octet-stream", opts...); err != nil {
+			return "", err
+		}
+	}
+	return digest, nil
+}
@@ -0,0 +1,227 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is wrapped by every error Chaos injects.
+var ErrInjectedFault = errors.New("s3client: injected fault")
+
+// ChaosConfig sets the probability (0 to 1) of each fault per call.
+type ChaosConfig struct {
+	// Seed makes the fault sequence reproducible for calls made in the
+	// same order.
+	Seed uint64
+	// Latency is added to every call, plus a random share of Jitter.
+	Latency time.Duration
+	Jitter  time.Duration
+	// ThrottleRate fails calls with a 503 SlowDown error.
+	ThrottleRate float64
+	// ServerErrorRate fails calls with a 500 InternalError.
+	ServerErrorRate float64
+	// TruncateRate cuts GetObject bodies short with io.ErrUnexpectedEOF.
+	TruncateRate float64
+}
+
+// Chaos wraps an ObjectAPI and injects latency and failures into its calls,
+// for testing retry and error handling. Injected errors carry the error
+// code and HTTP status that smithy.APIError and retry checks look at.
+type Chaos struct {
+	next ObjectAPI
+	cfg  ChaosConfig
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func NewChaos(next ObjectAPI, cfg ChaosConfig) *Chaos {
+	return &Chaos{next: next, cfg: cfg, rng: rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))}
+}
+
+var _ ObjectAPI = (*Chaos)(nil)
+
+type chaosError struct {
+	op     string
+	code   string
+	status int
+}
+
+func (e *chaosError) Error() string {
+	return fmt.Sprintf("s3client: injected %s (HTTP %d) in %s", e.code, e.status, e.op)
+}
+
+func (e *chaosError) ErrorCode() string    { return e.code }
+func (e *chaosError) ErrorMessage() string { return "injected fault" }
+func (e *chaosError) HTTPStatusCode() int  { return e.status }
+func (e *chaosError) Unwrap() error        { return ErrInjectedFault }
+
+func (ch *Chaos) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.rng.Float64() < rate
+}
+
+// inject delays the call and returns the fault to fail it with, if any.
+func (ch *Chaos) inject(ctx context.Context, op string) error {
+	delay := ch.cfg.Latency
+	if ch.cfg.Jitter > 0 {
+		ch.mu.Lock()
+		delay += time.Duration(ch.rng.Int64N(int64(ch.cfg.Jitter)))
+		ch.mu.Unlock()
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	switch {
+	case ch.roll(ch.cfg.ThrottleRate):
+		return &chaosError{op: op, code: "SlowDown", status: http.StatusServiceUnavailable}
+	case ch.roll(ch.cfg.ServerErrorRate):
+		return &chaosError{op: op, code: "InternalError", status: http.StatusInternalServerError}
+	}
+	return nil
+}
+
+// truncate returns how many bytes of r to pass through, or -1 to leave it.
+func (ch *Chaos) truncate() int64 {
+	if !ch.roll(ch.cfg.TruncateRate) {
+		return -1
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.rng.Int64N(64 << 10)
+}
+
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (ch *Chaos) PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string, opts ...CallOption) error {
+	if err := ch.inject(ctx, "PutObject"); err != nil {
+		return err
+	}
+	return ch.next.PutObject(ctx, bucket, key, body, contentType, opts...)
+}
+
+func (ch *Chaos) PutObjectBytes(ctx context.Context, bucket, key string, data []byte, contentType string, opts ...CallOption) error {
+	if err := ch.inject(ctx, "PutObject"); err != nil {
+		return err
+	}
+	return ch.next.PutObjectBytes(ctx, bucket, key, data, contentType, opts...)
+}
+
+func (ch *Chaos) GetObject(ctx context.Context, bucket, key string, opts ...CallOption) (io.ReadCloser, error) {
+	if err := ch.inject(ctx, "GetObject"); err != nil {
+		return nil, err
+	}
+	body, err := ch.next.GetObject(ctx, bucket, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if n := ch.truncate(); n >= 0 {
+		return &truncatedBody{ReadCloser: body, remaining: n}, nil
+	}
+	return body, nil
+}
+
+func (ch *Chaos) GetObjectBytes(ctx context.Context, bucket, key string, opts ...CallOption) ([]byte, error) {
+	if err := ch.inject(ctx, "GetObject"); err != nil {
+		return nil, err
+	}
+	data, err := ch.next.GetObjectBytes(ctx, bucket, key, opts...)
+	if err == nil && ch.truncate() >= 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInjectedFault, io.ErrUnexpectedEOF)
+	}
+	return data, err
+}
+
+func (ch *Chaos) StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	if err := ch.inject(ctx, "HeadObject"); err != nil {
+		return ObjectInfo{}, err
+	}
+	return ch.next.StatObject(ctx, bucket, key)
+}
+
+func (ch *Chaos) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	if err := ch.inject(ctx, "HeadObject"); err != nil {
+		return false, err
+	}
+	return ch.next.ObjectExists(ctx, bucket, key)
+}
+
+func (ch *Chaos) DeleteObject(ctx context.Context, bucket, key string) error {
+	if err := ch.inject(ctx, "DeleteObject"); err != nil {
+		return err
+	}
+	return ch.next.DeleteObject(ctx, bucket, key)
+}
+
+func (ch *Chaos) DeleteObjects(ctx context.Context, bucket string, keys []string) error {
+	if err := ch.inject(ctx, "DeleteObjects"); err != nil {
+		return err
+	}
+	return ch.next.DeleteObjects(ctx, bucket, keys)
+}
+
+func (ch *Chaos) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	if err := ch.inject(ctx, "CopyObject"); err != nil {
+		return err
+	}
+	return ch.next.CopyObject(ctx, srcBucket, srcKey, dstBucket, dstKey)
+}
+
+func (ch *Chaos) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	if err := ch.inject(ctx, "ListObjectsV2"); err != nil {
+		return nil, err
+	}
+	return ch.next.ListObjects(ctx, bucket, prefix)
+}
+
+func (ch *Chaos) ListObjectsDetailed(ctx context.Context, bucket, prefix string, opts ListOptions) ([]ObjectInfo, error) {
+	if err := ch.inject(ctx, "ListObjectsV2"); err != nil {
+		return nil, err
+	}
+	return ch.next.ListObjectsDetailed(ctx, bucket, prefix, opts)
+}
+
+func (ch *Chaos) UploadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error {
+	if err := ch.inject(ctx, "PutObject"); err != nil {
+		return err
+	}
+	return ch.next.UploadFile(ctx, bucket, key, localPath, opts...)
+}
+
+func (ch *Chaos) DownloadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error {
+	if err := ch.inject(ctx, "GetObject"); err != nil {
+		return err
+	}
+	return ch.next.DownloadFile(ctx, bucket, key, localPath, opts...)
+}
@@ -0,0 +1,105 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// CheckpointStore persists the progress of long-running bulk operations so
+// they can resume after a failure or restart.
+type CheckpointStore interface {
+	// Load returns the saved value of id, or "" when there is none.
+	Load(ctx context.Context, id string) (string, error)
+	Save(ctx context.Context, id, value string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// Checkpoint names the entry of Store an operation records its progress
+// in. ID defaults to the bucket and prefix operated on.
+type Checkpoint struct {
+	Store CheckpointStore
+	ID    string
+}
+
+func (cp Checkpoint) id(bucket, prefix string) string {
+	if cp.ID != "" {
+		return cp.ID
+	}
+	return bucket + "/" + prefix
+}
+
+type fileCheckpoints struct {
+	dir string
+}
+
+// NewFileCheckpointStore keeps checkpoints as files in dir, which is
+// created when needed.
+func NewFileCheckpointStore(dir string) CheckpointStore {
+	return fileCheckpoints{dir: dir}
+}
+
+func (s fileCheckpoints) path(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+".checkpoint")
+}
+
+func (s fileCheckpoints) Load(ctx context.Context, id string) (string, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+func (s fileCheckpoints) Save(ctx context.Context, id, value string) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	return writeFile(s.path(id), func(f *os.File) error {
+		_, err := f.WriteString(value)
+		return err
+	})
+}
+
+func (s fileCheckpoints) Delete(ctx context.Context, id string) error {
+	err := os.Remove(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+type objectCheckpoints struct {
+	c      *Client
+	bucket string
+	prefix string
+}
+
+// NewObjectCheckpointStore keeps checkpoints as objects under prefix in
+// bucket, which must not be a bucket being emptied.
+func (c *Client) NewObjectCheckpointStore(bucket, prefix string) CheckpointStore {
+	return objectCheckpoints{c: c, bucket: bucket, prefix: prefix}
+}
+
+func (s objectCheckpoints) key(id string) string {
+	return s.prefix + url.PathEscape(id)
+}
+
+func (s objectCheckpoints) Load(ctx context.Context, id string) (string, error) {
+	data, err := s.c.GetObjectBytes(ctx, s.bucket, s.key(id))
+	if isNotFound(err) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+func (s objectCheckpoints) Save(ctx context.Context, id, value string) error {
+	return s.c.PutObjectBytes(ctx, s.bucket, s.key(id), []byte(value), "text/plain", WithCompression(CompressionNone))
+}
+
+func (s objectCheckpoints) Delete(ctx context.Context, id string) error {
+	return s.c.DeleteObject(ctx, s.bucket, s.key(id))
+}
@@ -3,11 +3,12 @@ package s3client
 import (
 	"bytes"
 	"context"
-	"errors"
-	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/mkchar/s3client/utils"
@@ -18,47 +19,190 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
-	"github.com/aws/smithy-go"
 )
 
 type Client struct {
-	s3Client   *s3.Client
-	uploader   *manager.Uploader
-	downloader *manager.Downloader
-	cfg        Config
+	s3Client     *s3.Client
+	uploader     *manager.Uploader
+	downloader   *manager.Downloader
+	cache        *objectCache
+	diskCache    *diskCache
+	presignCache *presignCache
+	throttle     *throttleCounters
+	stats        *statsCounters
+	transport    *http.Transport
+	creds        *rotatingCredentials
+	life         *lifecycle
+	drain        *drainer
+	listV1       atomic.Bool
+	cfg          Config
 }
 
 func New(cfg Config) (*Client, error) {
+	profile, err := profileFor(cfg.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Region == "" {
+		cfg.Region = profile.region
+	}
 	if cfg.Region == "" {
 		cfg.Region = "us-east-1"
 	}
+	var provider aws.CredentialsProvider = credentials.NewStaticCredentialsProvider(
+		cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	if cfg.Credentials != nil {
+		provider = cfg.Credentials
+	}
+	creds := newRotatingCredentials(provider)
+
+	httpClient, transport, err := newHTTPClient(cfg.Transport)
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Transport = newSendfileTransport(httpClient.Transport)
+	var rec *recorder
+	if cfg.Recorder != nil && cfg.Recorder.Mode != RecordOff {
+		if rec, err = newRecorder(httpClient.Transport, *cfg.Recorder, cfg.AccessKeyID, cfg.SecretAccessKey); err != nil {
+			return nil, err
+		}
+		httpClient.Transport = rec
+	}
 	awsCfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			cfg.AccessKeyID, cfg.SecretAccessKey, "")),
+		config.WithHTTPClient(httpClient),
+		config.WithCredentialsProvider(creds.cache),
 		config.WithRegion(cfg.Region),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	throttle := &throttleCounters{}
+	stats := newStatsCounters()
+	drain := newDrainer()
 	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
 		o.Region = cfg.Region
-		o.BaseEndpoint = aws.String(cfg.Endpoint)
-		o.UsePathStyle = true
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		profile.apply(o)
+		o.UsePathStyle = cfg.usePathStyle(profile)
+		o.UseARNRegion = cfg.UseARNRegion
+		o.EndpointResolverV2 = &endpointResolver{
+			next:    o.EndpointResolverV2,
+			custom:  cfg.EndpointResolver,
+			cfg:     cfg,
+			profile: profile,
+		}
+		if cfg.RequestChecksumCalculation != aws.RequestChecksumCalculationUnset {
+			o.RequestChecksumCalculation = cfg.RequestChecksumCalculation
+		}
+		if cfg.ResponseChecksumValidation != aws.ResponseChecksumValidationUnset {
+			o.ResponseChecksumValidation = cfg.ResponseChecksumValidation
+		}
+		if cfg.DisableChunkedEncoding {
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		}
+		if cfg.SigningRegion != "" {
+			s3.WithSigV4SigningRegion(cfg.SigningRegion)(o)
+		}
+		if cfg.SigningName != "" {
+			s3.WithSigV4SigningName(cfg.SigningName)(o)
+		}
+		if cfg.RetryMode != "" {
+			o.RetryMode = cfg.RetryMode
+		}
+		if cfg.MaxAttempts > 0 {
+			o.RetryMaxAttempts = cfg.MaxAttempts
+		}
+		if cfg.Logger != nil {
+			o.Logger = cfg.Logger
+		}
+		o.APIOptions = append(o.APIOptions, drain.addMiddleware, addOperationError, addCallOptions, addFileSection, throttle.addMiddleware, stats.addMiddleware)
+		if cfg.Audit != nil && cfg.Audit.Sink != nil {
+			o.APIOptions = append(o.APIOptions, newAuditMiddleware(*cfg.Audit, creds.cache))
+		}
+		if cfg.ContentMD5 {
+			o.APIOptions = append(o.APIOptions, addContentMD5)
+		}
+		if len(cfg.DefaultHeaders) > 0 {
+			o.APIOptions = append(o.APIOptions, newDefaultHeaders(cfg.DefaultHeaders))
+		}
+		if cfg.CircuitBreaker != nil {
+			o.APIOptions = append(o.APIOptions, newCircuitBreaker(*cfg.CircuitBreaker).addMiddleware)
+		}
 	})
 
-	return &Client{
-		s3Client:   s3Client,
-		uploader:   manager.NewUploader(s3Client),
-		downloader: manager.NewDownloader(s3Client),
-		cfg:        cfg,
-	}, nil
+	c := &Client{
+		s3Client: s3Client,
+		uploader: manager.NewUploader(s3Client, func(u *manager.Uploader) {
+			u.RequestChecksumCalculation = s3Client.Options().RequestChecksumCalculation
+			if cfg.UploadBufferSize > 0 {
+				u.BufferProvider = manager.NewBufferedReadSeekerWriteToPool(cfg.UploadBufferSize)
+			}
+		}),
+		downloader: manager.NewDownloader(s3Client, func(d *manager.Downloader) {
+			if cfg.DownloadBufferSize > 0 {
+				d.BufferProvider = manager.NewPooledBufferedWriterReadFromProvider(cfg.DownloadBufferSize)
+			}
+		}),
+		throttle:  throttle,
+		stats:     stats,
+		transport: transport,
+		creds:     creds,
+		life:      &lifecycle{},
+		drain:     drain,
+		cfg:       cfg,
+	}
+	if rec != nil {
+		c.onClose(rec.close)
+	}
+	if cfg.Cache != nil {
+		c.cache = newObjectCache(*cfg.Cache)
+	}
+	if cfg.PresignCache != nil {
+		c.presignCache = newPresignCache(*cfg.PresignCache)
+	}
+	if cfg.DiskCache != nil {
+		if c.diskCache, err = newDiskCache(*cfg.DiskCache); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 func (c *Client) CreateBucket(ctx context.Context, name string) error {
-	_, err := c.s3Client.CreateBucket(ctx, &s3.CreateBucketInput{
-		Bucket: aws.String(name),
-	})
+	return c.CreateBucketWithOptions(ctx, name, CreateBucketOptions{})
+}
+
+type CreateBucketOptions struct {
+	// Region is sent as the LocationConstraint; it defaults to
+	// Config.Region and is omitted for us-east-1.
+	Region          string
+	ACL             types.BucketCannedACL
+	ObjectLock      bool
+	ObjectOwnership types.ObjectOwnership
+}
+
+func (c *Client) CreateBucketWithOptions(ctx context.Context, name string, opts CreateBucketOptions) error {
+	input := &s3.CreateBucketInput{
+		Bucket:          aws.String(name),
+		ACL:             opts.ACL,
+		ObjectOwnership: opts.ObjectOwnership,
+	}
+	region := opts.Region
+	if region == "" {
+		region = c.cfg.Region
+	}
+	if region != "" && region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+	if opts.ObjectLock {
+		input.ObjectLockEnabledForBucket = aws.Bool(true)
+	}
+	_, err := c.s3Client.CreateBucket(ctx, input)
 	return err
 }
 
@@ -73,14 +217,7 @@ func (c *Client) BucketExists(ctx context.Context, name string) (bool, error) {
 	_, err := c.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(name),
 	})
-	if err != nil {
-		var apiErr smithy.APIError
-		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NotFound" {
-			return false, nil
-		}
-		return false, err
-	}
-	return true, nil
+	return existsResult(err)
 }
 
 func (c *Client) WaitBucketExists(ctx context.Context, name string, timeout time.Duration) error {
@@ -90,6 +227,22 @@ func (c *Client) WaitBucketExists(ctx context.Context, name string, timeout time
 	}, timeout)
 }
 
+func (c *Client) WaitObjectExists(ctx context.Context, bucket, key string, timeout time.Duration) error {
+	waiter := s3.NewObjectExistsWaiter(c.s3Client)
+	return waiter.Wait(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, timeout)
+}
+
+func (c *Client) WaitObjectNotExists(ctx context.Context, bucket, key string, timeout time.Duration) error {
+	waiter := s3.NewObjectNotExistsWaiter(c.s3Client)
+	return waiter.Wait(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, timeout)
+}
+
 func (c *Client) ListBuckets(ctx context.Context) ([]string, error) {
 	resp, err := c.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
 	if err != nil {
@@ -104,41 +257,151 @@ func (c *Client) ListBuckets(ctx context.Context) ([]string, error) {
 	return buckets, nil
 }
 
-func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string) error {
-	_, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+type BucketSummary struct {
+	Name         string
+	CreationDate time.Time
+	Region       string
+}
+
+type ListBucketsOptions struct {
+	Prefix string
+	// Region limits the listing to buckets in one region on AWS.
+	Region string
+	// MaxBuckets is the page size; by default the backend decides.
+	MaxBuckets int32
+}
+
+// ListBucketsDetailed returns every bucket with its creation date, following
+// ListBuckets pagination. Prefix is also applied client-side for backends
+// that ignore it.
+func (c *Client) ListBucketsDetailed(ctx context.Context, opts ListBucketsOptions) ([]BucketSummary, error) {
+	input := &s3.ListBucketsInput{}
+	if opts.Prefix != "" {
+		input.Prefix = aws.String(opts.Prefix)
+	}
+	if opts.Region != "" {
+		input.BucketRegion = aws.String(opts.Region)
+	}
+	if opts.MaxBuckets > 0 {
+		input.MaxBuckets = aws.Int32(opts.MaxBuckets)
+	}
+	var buckets []BucketSummary
+	paginator := s3.NewListBucketsPaginator(c.s3Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, bucket := range page.Buckets {
+			name := aws.ToString(bucket.Name)
+			if name == "" || !strings.HasPrefix(name, opts.Prefix) {
+				continue
+			}
+			buckets = append(buckets, BucketSummary{
+				Name:         name,
+				CreationDate: aws.ToTime(bucket.CreationDate),
+				Region:       aws.ToString(bucket.BucketRegion),
+			})
+		}
+	}
+	return buckets, nil
+}
+
+func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string, opts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, opts)
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(bucket),
 		Key:         aws.String(key),
 		Body:        body,
 		ContentType: aws.String(contentType),
-	})
+	}
+	c.applyCacheControl(input)
+	c.invalidate(bucket, key)
+	size := readerSize(body)
+	if o.compression != CompressionNone {
+		return c.putCompressed(ctx, input, size, o)
+	}
+	if size < 0 {
+		_, err := c.upload(ctx, input, o.uploaderOptions)
+		return err
+	}
+	_, err := c.s3Client.PutObject(ctx, input)
+	return err
+}
+
+// PutObjectStream uploads a body of unknown length, such as a pipe, with
+// the multipart uploader: parts are sent as they fill, so memory stays at
+// part size times concurrency, which WithMemoryLimit can bound. Bodies
+// shorter than one part take a single PutObject. An upload has at most
+// 10,000 parts, so raise WithPartSize above its 5 MiB default for streams
+// larger than about 48 GiB. PutObject does the same for bodies whose
+// length it cannot determine.
+func (c *Client) PutObjectStream(ctx context.Context, bucket, key string, body io.Reader, contentType string, opts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, opts)
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	c.applyCacheControl(input)
+	c.invalidate(bucket, key)
+	if o.compression != CompressionNone {
+		return c.putCompressed(ctx, input, -1, o)
+	}
+	_, err := c.upload(ctx, input, o.uploaderOptions)
 	return err
 }
 
-func (c *Client) PutObjectBytes(ctx context.Context, bucket, key string, data []byte, contentType string) error {
-	return c.PutObject(ctx, bucket, key, bytes.NewReader(data), contentType)
+func (c *Client) PutObjectBytes(ctx context.Context, bucket, key string, data []byte, contentType string, opts ...CallOption) error {
+	return c.PutObject(ctx, bucket, key, bytes.NewReader(data), contentType, opts...)
 }
 
-func (c *Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-	output, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+func (c *Client) GetObject(ctx context.Context, bucket, key string, opts ...CallOption) (io.ReadCloser, error) {
+	ctx, o := c.callOptions(ctx, opts)
+	output, err := c.getObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	})
+	}, o)
 	if err != nil {
 		return nil, err
 	}
+	return c.objectBody(output, o)
+}
+
+func (c *Client) objectBody(output *s3.GetObjectOutput, o callOptions) (io.ReadCloser, error) {
+	if o.decompress && output.ContentEncoding != nil {
+		return decompressBody(output.Body, *output.ContentEncoding)
+	}
 	return output.Body, nil
 }
 
-func (c *Client) GetObjectBytes(ctx context.Context, bucket, key string) ([]byte, error) {
-	obj, err := c.GetObject(ctx, bucket, key)
+func (c *Client) GetObjectBytes(ctx context.Context, bucket, key string, opts ...CallOption) ([]byte, error) {
+	ctx, o := c.callOptions(ctx, opts)
+	if c.cache != nil {
+		return c.cachedObjectBytes(ctx, bucket, key, o)
+	}
+	output, err := c.getObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, o)
 	if err != nil {
 		return nil, err
 	}
-	defer obj.Close()
-	return io.ReadAll(obj)
+	defer output.Body.Close()
+	if err := o.checkInMemorySize(bucket, key, getObjectInfo(key, output)); err != nil {
+		return nil, err
+	}
+	body, err := c.objectBody(output, o)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return o.readAll(bucket, key, body)
 }
 
 func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	c.invalidate(bucket, key)
 	_, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
@@ -151,18 +414,11 @@ func (c *Client) ObjectExists(ctx context.Context, bucket, key string) (bool, er
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
-	if err != nil {
-		var apiErr smithy.APIError
-		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NotFound" {
-			return false, nil
-		}
-		return false, err
-	}
-	return true, nil
+	return existsResult(err)
 }
 
 func (c *Client) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
-	resp, err := c.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+	resp, err := c.listObjectsV2(ctx, &s3.ListObjectsV2Input{
 		Bucket: aws.String(bucket),
 		Prefix: aws.String(prefix),
 	})
@@ -181,50 +437,119 @@ func (c *Client) ListObjects(ctx context.Context, bucket, prefix string) ([]stri
 func (c *Client) DeleteObjects(ctx context.Context, bucket string, keys []string) error {
 	var deleteObjects []types.ObjectIdentifier
 	for _, key := range keys {
+		c.invalidate(bucket, key)
 		deleteObjects = append(deleteObjects, types.ObjectIdentifier{Key: aws.String(key)})
 	}
 
-	_, err := c.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
-		Bucket: aws.String(bucket),
-		Delete: &types.Delete{Objects: deleteObjects},
-	})
-	return err
+	return c.deleteIdentifiers(ctx, bucket, deleteObjects)
+}
+
+// UploadFile uploads localPath, in parts for large files. On Linux, parts
+// sent to plain HTTP endpoints are handed to sendfile(2) instead of being
+// copied through user space. HTTPS endpoints always copy, and over plain
+// HTTP SigV4 still reads each part once to hash the payload, so this saves
+// the send copy, not the read.
+func (c *Client) UploadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, opts)
+	return c.uploadFile(ctx, bucket, key, localPath, nil, o)
 }
 
-func (c *Client) UploadFile(ctx context.Context, bucket, key, localPath string) error {
+func (c *Client) uploadFile(ctx context.Context, bucket, key, localPath string, metadata map[string]string, o callOptions) error {
 	file, err := os.Open(localPath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	_, err = c.uploader.Upload(ctx, &s3.PutObjectInput{
+	if o.skipUnchanged {
+		digest, err := digestFile(file)
+		if err != nil {
+			return err
+		}
+		info, err := c.StatObject(ctx, bucket, key)
+		if err == nil {
+			same, err := digest.unchanged(file, info, o.partSize)
+			if err != nil || same {
+				return err
+			}
+		} else if !isNotFound(err) {
+			return err
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[MetaContentSHA256] = digest.sha256
+	}
+
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(bucket),
 		Key:         aws.String(key),
 		Body:        file,
 		ContentType: aws.String(utils.DetectContentType(path.Ext(localPath))),
-	})
+		Metadata:    metadata,
+	}
+	c.applyCacheControl(input)
+	c.invalidate(bucket, key)
+	if o.compression != CompressionNone {
+		return c.putCompressed(ctx, input, readerSize(file), o)
+	}
+	_, err = c.upload(ctx, input, o.uploaderOptions)
 	return err
 }
 
-func (c *Client) DownloadFile(ctx context.Context, bucket, key, localPath string) error {
-	file, err := os.Create(localPath)
+func (c *Client) DownloadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error {
+	ctx, done, err := c.drain.begin(ctx)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	_, err = c.downloader.Download(ctx, file, &s3.GetObjectInput{
+	defer done()
+	ctx, o := c.callOptions(ctx, opts)
+	head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	if o.decompress && head.ContentEncoding != nil && isCompressed(*head.ContentEncoding) {
+		return c.downloadDecompressed(ctx, bucket, key, localPath, opts)
+	}
+
+	// The file is preallocated to the object size and the downloader writes
+	// each part at its offset, pinned to the ETag the size came from.
+	return writeFile(localPath, func(file *os.File) error {
+		if size := aws.ToInt64(head.ContentLength); size > 0 {
+			if err := file.Truncate(size); err != nil {
+				return err
+			}
+		}
+		_, err := c.downloader.Download(ctx, file, &s3.GetObjectInput{
+			Bucket:  aws.String(bucket),
+			Key:     aws.String(key),
+			IfMatch: head.ETag,
+		}, o.downloaderOptions)
+		return err
+	})
+}
+
+func (c *Client) downloadDecompressed(ctx context.Context, bucket, key, localPath string, opts []CallOption) error {
+	body, err := c.GetObject(ctx, bucket, key, opts...)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return writeFile(localPath, func(file *os.File) error {
+		_, err := io.Copy(file, body)
+		return err
+	})
 }
 
 func (c *Client) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	c.invalidate(dstBucket, dstKey)
 	_, err := c.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
 		Bucket:     aws.String(dstBucket),
-		CopySource: aws.String(fmt.Sprintf("%s/%s", srcBucket, srcKey)),
+		CopySource: aws.String(copySource(srcBucket, srcKey)),
 		Key:        aws.String(dstKey),
 	})
 	return err
@@ -237,23 +562,45 @@ func (c *Client) MoveObject(ctx context.Context, bucket, srcKey, dstKey string)
 	return c.DeleteObject(ctx, bucket, srcKey)
 }
 
-func (c *Client) PresignGetObject(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
-	presigner := s3.NewPresignClient(c.s3Client)
-	presignReq, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+func (c *Client) PresignGetObject(ctx context.Context, bucket, key string, expiry time.Duration, opts ...CallOption) (string, error) {
+	ctx, o := c.callOptions(ctx, opts)
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	}, func(opts *s3.PresignOptions) {
+	}
+	if o.versionID != "" {
+		input.VersionId = aws.String(o.versionID)
+	}
+	cacheKey := presignCacheKey{bucket: bucket, key: key, versionID: o.versionID, expiry: expiry}
+	now := time.Now()
+	if c.presignCache != nil {
+		if url, ok := c.presignCache.get(cacheKey, now); ok {
+			c.auditCachedPresign(ctx, "GetObject", bucket, key)
+			return url, nil
+		}
+	}
+	presigner := s3.NewPresignClient(c.s3Client)
+	presignReq, err := presigner.PresignGetObject(withPresignAudit(ctx), input, func(opts *s3.PresignOptions) {
 		opts.Expires = expiry
 	})
 	if err != nil {
 		return "", err
 	}
+	if c.presignCache != nil {
+		expires := now.Add(expiry)
+		// URLs signed with temporary credentials stop working when the
+		// credentials expire.
+		if creds, err := c.creds.cache.Retrieve(ctx); err == nil && creds.CanExpire && creds.Expires.Before(expires) {
+			expires = creds.Expires
+		}
+		c.presignCache.put(cacheKey, presignReq.URL, expires, now)
+	}
 	return presignReq.URL, nil
 }
 
 func (c *Client) PresignPutObject(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
 	presigner := s3.NewPresignClient(c.s3Client)
-	presignReq, err := presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+	presignReq, err := presigner.PresignPutObject(withPresignAudit(ctx), &s3.PutObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	}, func(opts *s3.PresignOptions) {
@@ -275,3 +622,10 @@ func (c *Client) EmptyBucket(ctx context.Context, bucket string) error {
 	}
 	return nil
 }
+
+// EmptyBucketResumable empties bucket, resuming from cp as
+// DeletePrefixResumable does.
+func (c *Client) EmptyBucketResumable(ctx context.Context, bucket string, cp Checkpoint) error {
+	_, err := c.DeletePrefixResumable(ctx, bucket, "", ListOptions{}, cp)
+	return err
+}
@@ -0,0 +1,98 @@
+package s3client
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/logging"
+)
+
+// ClientOption overrides a setting of a client returned by With.
+type ClientOption func(*Config)
+
+func WithRegion(region string) ClientOption {
+	return func(cfg *Config) {
+		cfg.Region = region
+	}
+}
+
+func WithEndpoint(endpoint string) ClientOption {
+	return func(cfg *Config) {
+		cfg.Endpoint = endpoint
+	}
+}
+
+func WithDefaultBucket(bucket string) ClientOption {
+	return func(cfg *Config) {
+		cfg.DefaultBucket = bucket
+	}
+}
+
+// WithRetryPolicy replaces the retryer; a zero maxAttempts keeps the SDK
+// default for mode.
+func WithRetryPolicy(mode aws.RetryMode, maxAttempts int) ClientOption {
+	return func(cfg *Config) {
+		cfg.RetryMode = mode
+		cfg.MaxAttempts = maxAttempts
+	}
+}
+
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(cfg *Config) {
+		cfg.Logger = logger
+	}
+}
+
+// With returns a shallow clone of c with opts applied. Only the region,
+// endpoint, default bucket, retry policy and logger can differ; the clone
+// shares the HTTP transport, credentials, statistics and middleware state
+// with c. Caches are shared unless the endpoint changes. Closing the clone
+// does not stop background work of c.
+func (c *Client) With(opts ...ClientOption) *Client {
+	cfg := c.cfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	profile, _ := profileFor(cfg.Provider)
+	// Options are changed before s3.New so that it resolves a new retryer.
+	o := c.s3Client.Options()
+	o.Region = cfg.Region
+	if cfg.Endpoint != "" {
+		o.BaseEndpoint = aws.String(cfg.Endpoint)
+	}
+	o.UsePathStyle = cfg.usePathStyle(profile)
+	if r, ok := o.EndpointResolverV2.(*endpointResolver); ok {
+		o.EndpointResolverV2 = &endpointResolver{next: r.next, custom: r.custom, cfg: cfg, profile: profile}
+	}
+	if cfg.RetryMode != c.cfg.RetryMode || cfg.MaxAttempts != c.cfg.MaxAttempts {
+		o.Retryer = nil
+		o.RetryMode = cfg.RetryMode
+		o.RetryMaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.Logger != nil {
+		o.Logger = cfg.Logger
+	}
+	s3Client := s3.New(o)
+
+	uploader, downloader := *c.uploader, *c.downloader
+	uploader.S3, downloader.S3 = s3Client, s3Client
+	clone := &Client{
+		s3Client:     s3Client,
+		uploader:     &uploader,
+		downloader:   &downloader,
+		cache:        c.cache,
+		diskCache:    c.diskCache,
+		presignCache: c.presignCache,
+		throttle:     c.throttle,
+		stats:        c.stats,
+		transport:    c.transport,
+		creds:        c.creds,
+		life:         &lifecycle{},
+		drain:        c.drain,
+		cfg:          cfg,
+	}
+	if cfg.Endpoint != c.cfg.Endpoint {
+		clone.cache, clone.diskCache, clone.presignCache = nil, nil, nil
+	}
+	clone.listV1.Store(c.listV1.Load())
+	return clone
+}
@@ -0,0 +1,138 @@
+// Command s3client exposes maintenance utilities of the s3client package.
+//
+//	s3client bench -endpoint http://localhost:9000 -bucket test -sizes 64KiB,4MiB
+//
+// Credentials are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mkchar/s3client"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var err error
+	switch os.Args[1] {
+	case "bench":
+		err = bench(ctx, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: s3client bench [flags]")
+	os.Exit(2)
+}
+
+// clientFlags registers the connection flags shared by all subcommands.
+func clientFlags(fs *flag.FlagSet) func() (*s3client.Client, error) {
+	endpoint := fs.String("endpoint", os.Getenv("AWS_ENDPOINT_URL_S3"), "S3 endpoint URL")
+	region := fs.String("region", os.Getenv("AWS_REGION"), "region")
+	provider := fs.String("provider", "", "provider profile: aws, minio, r2, b2, ceph or wasabi")
+	return func() (*s3client.Client, error) {
+		return s3client.New(s3client.Config{
+			Provider:        s3client.Provider(*provider),
+			Endpoint:        *endpoint,
+			Region:          *region,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		})
+	}
+}
+
+func bench(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	newClient := clientFlags(fs)
+	bucket := fs.String("bucket", "", "bucket to benchmark against (required)")
+	sizes := fs.String("sizes", "1MiB", "comma-separated object sizes")
+	concurrency := fs.Int("concurrency", 8, "parallel workers")
+	duration := fs.Duration("duration", 10*time.Second, "duration of each PUT and GET phase")
+	prefix := fs.String("prefix", "", "prefix for the temporary objects")
+	fs.Parse(args)
+	if *bucket == "" {
+		return fmt.Errorf("bench: -bucket is required")
+	}
+	opts := s3client.BenchmarkOptions{Concurrency: *concurrency, Duration: *duration, Prefix: *prefix}
+	for _, s := range strings.Split(*sizes, ",") {
+		size, err := parseSize(s)
+		if err != nil {
+			return err
+		}
+		opts.Sizes = append(opts.Sizes, size)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	results, err := c.Benchmark(ctx, *bucket, opts)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "size\top\tops/s\tMiB/s\tp50\tp90\tp99\tmax\terrors\t")
+	for _, r := range results {
+		for _, row := range []struct {
+			op string
+			r  s3client.BenchmarkOpResult
+		}{{"PUT", r.Put}, {"GET", r.Get}} {
+			fmt.Fprintf(tw, "%s\t%s\t%.1f\t%.1f\t%v\t%v\t%v\t%v\t%d\t\n",
+				formatSize(r.Size), row.op, row.r.OpsPerSecond(), row.r.Throughput()/(1<<20),
+				row.r.P50.Round(time.Microsecond), row.r.P90.Round(time.Microsecond),
+				row.r.P99.Round(time.Microsecond), row.r.Max.Round(time.Microsecond), row.r.Errors)
+		}
+	}
+	tw.Flush()
+	return err
+}
+
+var sizeUnits = []struct {
+	suffix string
+	scale  int64
+}{
+	{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"B", 1},
+}
+
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	scale := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(strings.ToUpper(s), strings.ToUpper(u.suffix)) {
+			s, scale = strings.TrimSpace(s[:len(s)-len(u.suffix)]), u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * scale, nil
+}
+
+func formatSize(n int64) string {
+	for _, u := range sizeUnits[:3] {
+		if n >= u.scale && n%u.scale == 0 {
+			return fmt.Sprintf("%d%s", n/u.scale, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
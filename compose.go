@@ -0,0 +1,221 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	minPartSize = 5 << 20
+	maxPartSize = 5 << 30
+	maxParts    = 10000
+)
+
+// ComposeObjects concatenates srcKeys, in order, into dstKey using a
+// multipart upload. Sources of at least 5MB are copied server-side with
+// UploadPartCopy; smaller ones are read and packed together until they
+// reach the minimum part size.
+func (c *Client) ComposeObjects(ctx context.Context, bucket, dstKey string, srcKeys ...string) error {
+	if len(srcKeys) == 0 {
+		return errors.New("s3client: no source objects to compose")
+	}
+	ctx, done, err := c.drain.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	sizes := make([]int64, len(srcKeys))
+	var contentType *string
+	for i, key := range srcKeys {
+		head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return err
+		}
+		sizes[i] = aws.ToInt64(head.ContentLength)
+		if i == 0 {
+			contentType = head.ContentType
+		}
+	}
+
+	create, err := c.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(dstKey),
+		ContentType: contentType,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.invalidate(bucket, dstKey)
+	comp := &composer{c: c, bucket: bucket, key: dstKey, uploadID: create.UploadId}
+	if err := comp.compose(ctx, srcKeys, sizes); err != nil {
+		c.abortMultipart(ctx, bucket, dstKey, create.UploadId)
+		return err
+	}
+	return comp.complete(ctx)
+}
+
+func (c *Client) abortMultipart(ctx context.Context, bucket, key string, uploadID *string) {
+	_, _ = c.s3Client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+}
+
+type composer struct {
+	c        *Client
+	bucket   string
+	key      string
+	uploadID *string
+	parts    []types.CompletedPart
+	buf      bytes.Buffer
+}
+
+func (p *composer) compose(ctx context.Context, srcKeys []string, sizes []int64) error {
+	for i, key := range srcKeys {
+		if err := p.add(ctx, key, sizes[i]); err != nil {
+			return err
+		}
+	}
+	if p.buf.Len() > 0 || len(p.parts) == 0 {
+		return p.flush(ctx)
+	}
+	return nil
+}
+
+func (p *composer) add(ctx context.Context, srcKey string, size int64) error {
+	var off int64
+	if p.buf.Len() > 0 {
+		take := min(int64(minPartSize-p.buf.Len()), size)
+		if err := p.read(ctx, srcKey, 0, take); err != nil {
+			return err
+		}
+		off = take
+		if p.buf.Len() >= minPartSize {
+			if err := p.flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	remaining := size - off
+	if remaining < minPartSize {
+		if remaining == 0 {
+			return nil
+		}
+		return p.read(ctx, srcKey, off, remaining)
+	}
+	for remaining > 0 {
+		n := min(remaining, maxPartSize)
+		if rest := remaining - n; rest > 0 && rest < minPartSize {
+			n = remaining - minPartSize
+		}
+		if err := p.copy(ctx, srcKey, off, n); err != nil {
+			return err
+		}
+		off += n
+		remaining -= n
+	}
+	return nil
+}
+
+func (p *composer) read(ctx context.Context, srcKey string, off, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	output, err := p.c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(srcKey),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+n-1)),
+	})
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+	_, err = io.CopyN(&p.buf, output.Body, n)
+	return err
+}
+
+func (p *composer) nextPart() (int32, error) {
+	if len(p.parts) >= maxParts {
+		return 0, fmt.Errorf("s3client: composed object exceeds %d parts", maxParts)
+	}
+	return int32(len(p.parts) + 1), nil
+}
+
+func (p *composer) flush(ctx context.Context) error {
+	num, err := p.nextPart()
+	if err != nil {
+		return err
+	}
+	output, err := p.c.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(p.bucket),
+		Key:        aws.String(p.key),
+		UploadId:   p.uploadID,
+		PartNumber: aws.Int32(num),
+		Body:       bytes.NewReader(p.buf.Bytes()),
+	})
+	if err != nil {
+		return err
+	}
+	p.buf.Reset()
+	p.parts = append(p.parts, types.CompletedPart{ETag: output.ETag, PartNumber: aws.Int32(num)})
+	return nil
+}
+
+func (p *composer) copy(ctx context.Context, srcKey string, off, n int64) error {
+	num, err := p.nextPart()
+	if err != nil {
+		return err
+	}
+	output, err := p.c.s3Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+		Bucket:          aws.String(p.bucket),
+		Key:             aws.String(p.key),
+		UploadId:        p.uploadID,
+		PartNumber:      aws.Int32(num),
+		CopySource:      aws.String(copySource(p.bucket, srcKey)),
+		CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", off, off+n-1)),
+	})
+	if err != nil {
+		return err
+	}
+	p.parts = append(p.parts, types.CompletedPart{ETag: output.CopyPartResult.ETag, PartNumber: aws.Int32(num)})
+	return nil
+}
+
+func (p *composer) complete(ctx context.Context) error {
+	_, err := p.c.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(p.bucket),
+		Key:             aws.String(p.key),
+		UploadId:        p.uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: p.parts},
+	})
+	if err != nil {
+		p.c.abortMultipart(ctx, p.bucket, p.key, p.uploadID)
+	}
+	return err
+}
+
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	if isAccessPointARN(bucket) {
+		return bucket + "/object/" + strings.Join(segments, "/")
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,141 @@
+package s3client
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/klauspost/compress/zstd"
+)
+
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// MetaUncompressedSize is the user metadata key holding the original body
+// size of objects stored with a Content-Encoding.
+const MetaUncompressedSize = "uncompressed-size"
+
+// putCompressed uploads input with o.compression and the transfer settings
+// of o.
+func (c *Client) putCompressed(ctx context.Context, input *s3.PutObjectInput, size int64, o callOptions) error {
+	alg := o.compression
+	if alg != CompressionGzip && alg != CompressionZstd {
+		return fmt.Errorf("s3client: unsupported compression %q", alg)
+	}
+	input.ContentEncoding = aws.String(string(alg))
+	if size >= 0 {
+		if input.Metadata == nil {
+			input.Metadata = map[string]string{}
+		}
+		input.Metadata[MetaUncompressedSize] = strconv.FormatInt(size, 10)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(compressTo(pw, input.Body, alg))
+	}()
+	// Closing the read side unblocks the compressor if the upload stops
+	// consuming the body early.
+	defer pr.Close()
+
+	input.Body = pr
+	_, err := c.upload(ctx, input, o.uploaderOptions)
+	return err
+}
+
+func newCompressWriter(w io.Writer, alg Compression) (io.WriteCloser, error) {
+	switch alg {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("s3client: unsupported compression %q", alg)
+}
+
+func compressTo(w io.Writer, r io.Reader, alg Compression) error {
+	enc, err := newCompressWriter(w, alg)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(enc, r); err != nil {
+		enc.Close()
+		return err
+	}
+	return enc.Close()
+}
+
+func decompressBody(body io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	switch Compression(strings.ToLower(strings.TrimSpace(encoding))) {
+	case CompressionGzip:
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		return &decompressReader{Reader: zr, closers: []io.Closer{zr, body}}, nil
+	case CompressionZstd:
+		zr, err := zstd.NewReader(body)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		rc := zr.IOReadCloser()
+		return &decompressReader{Reader: rc, closers: []io.Closer{rc, body}}, nil
+	}
+	return body, nil
+}
+
+func isCompressed(encoding string) bool {
+	switch Compression(strings.ToLower(strings.TrimSpace(encoding))) {
+	case CompressionGzip, CompressionZstd:
+		return true
+	}
+	return false
+}
+
+type decompressReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *decompressReader) Close() error {
+	var first error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case io.Seeker:
+		cur, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := v.Seek(cur, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - cur
+	}
+	return -1
+}
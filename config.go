@@ -1,8 +1,95 @@
 package s3client
 
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/logging"
+)
+
 type Config struct {
+	Provider        Provider
 	Endpoint        string
 	AccessKeyID     string
 	SecretAccessKey string
 	Region          string
+	DefaultBucket   string
+
+	// EndpointResolver, when set, picks the endpoint per request so one
+	// client can reach buckets behind different gateways.
+	EndpointResolver EndpointFunc
+	// SigningRegion and SigningName override the SigV4 credential scope
+	// for gateways that expect a fixed region or service name.
+	SigningRegion string
+	SigningName   string
+
+	AddressingStyle AddressingStyle
+	// UseARNRegion lets access point ARNs in another region than Region
+	// be addressed directly.
+	UseARNRegion bool
+
+	// Credentials, when set, is used instead of AccessKeyID and
+	// SecretAccessKey.
+	Credentials aws.CredentialsProvider
+
+	// RetryMode selects the SDK retryer; aws.RetryModeAdaptive adds
+	// client-side rate limiting when the backend throttles.
+	RetryMode   aws.RetryMode
+	MaxAttempts int
+
+	// Logger receives the SDK's log output.
+	Logger logging.Logger
+
+	// DefaultHeaders are added to every request, such as a tenant header a
+	// gateway requires. Presigned URLs are signed with them, so whoever
+	// uses such a URL has to send the same headers.
+	DefaultHeaders map[string]string
+
+	CircuitBreaker *CircuitBreakerConfig
+	Transport      *TransportConfig
+	Audit          *AuditConfig
+	// Recorder records or replays HTTP traffic for integration tests.
+	Recorder *RecorderConfig
+
+	// RequestChecksumCalculation and ResponseChecksumValidation override
+	// the SDK and provider defaults when set.
+	RequestChecksumCalculation aws.RequestChecksumCalculation
+	ResponseChecksumValidation aws.ResponseChecksumValidation
+	// DisableChunkedEncoding keeps uploads out of the aws-chunked framing
+	// used for trailing checksums, for backends that reject it. Request
+	// checksums are then only computed when an operation requires them.
+	DisableChunkedEncoding bool
+	// ContentMD5 attaches Content-MD5 to uploads and batch deletes with
+	// in-memory or seekable bodies, for buckets that require it.
+	ContentMD5 bool
+
+	Compression Compression
+	Decompress  bool
+
+	// CacheControl sets Cache-Control on PutObject, UploadFile and
+	// UploadDirectory from the uploaded content type.
+	CacheControl CacheControlPolicy
+
+	NativeAppend bool
+
+	// CASPrefix is where PutObjectCAS stores blobs; it defaults to "cas/".
+	CASPrefix string
+
+	// ListObjectsV1 lists with the v1 ListObjects API. The client also
+	// switches to it on its own after a v2 listing returns NotImplemented.
+	ListObjectsV1 bool
+
+	MaxInMemoryObjectSize int64
+	ReadRetries           int
+
+	// UploadBufferSize and DownloadBufferSize enable the transfer
+	// manager's pooled part buffers with the given size.
+	UploadBufferSize   int
+	DownloadBufferSize int
+
+	// MultipartThreshold is the body size from which Upload uses a
+	// multipart upload; it defaults to 16 MiB.
+	MultipartThreshold int64
+
+	Cache        *CacheConfig
+	DiskCache    *DiskCacheConfig
+	PresignCache *PresignCacheConfig
 }
@@ -0,0 +1,47 @@
+package s3client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// contentMD5Operation reports whether op gets a Content-MD5 header when
+// Config.ContentMD5 is set: object and part uploads, batch deletes and
+// the Put* configuration calls Object Lock buckets validate.
+func contentMD5Operation(op string) bool {
+	return op == "UploadPart" || op == "DeleteObjects" || strings.HasPrefix(op, "Put")
+}
+
+func addContentMD5(stack *middleware.Stack) error {
+	return stack.Build.Add(middleware.BuildMiddlewareFunc("s3client.ContentMD5",
+		func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+			req, ok := in.Request.(*smithyhttp.Request)
+			if !ok || !contentMD5Operation(middleware.GetOperationName(ctx)) || req.Header.Get("Content-MD5") != "" {
+				return next.HandleBuild(ctx, in)
+			}
+			// Streams that cannot be rewound are sent without it.
+			stream, ok := req.GetStream().(io.ReadSeeker)
+			if !ok || !req.IsStreamSeekable() {
+				return next.HandleBuild(ctx, in)
+			}
+			start, err := stream.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return middleware.BuildOutput{}, middleware.Metadata{}, err
+			}
+			h := md5.New()
+			if _, err := io.Copy(h, stream); err != nil {
+				return middleware.BuildOutput{}, middleware.Metadata{}, err
+			}
+			if _, err := stream.Seek(start, io.SeekStart); err != nil {
+				return middleware.BuildOutput{}, middleware.Metadata{}, err
+			}
+			req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(h.Sum(nil)))
+			return next.HandleBuild(ctx, in)
+		}), middleware.After)
+}
@@ -0,0 +1,49 @@
+package s3client
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// rotatingCredentials lets the provider behind a client be replaced while
+// the s3.Client, uploader and downloader built on top of it keep running.
+type rotatingCredentials struct {
+	provider atomic.Pointer[aws.CredentialsProvider]
+	cache    *aws.CredentialsCache
+}
+
+func newRotatingCredentials(p aws.CredentialsProvider) *rotatingCredentials {
+	r := &rotatingCredentials{}
+	r.provider.Store(&p)
+	r.cache = aws.NewCredentialsCache(r)
+	return r
+}
+
+func (r *rotatingCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	return (*r.provider.Load()).Retrieve(ctx)
+}
+
+func (r *rotatingCredentials) swap(p aws.CredentialsProvider) {
+	r.provider.Store(&p)
+	r.cache.Invalidate()
+}
+
+// SetCredentials replaces the static keys used to sign new requests.
+// Requests already signed, including parts of in-flight transfers, are not
+// affected.
+func (c *Client) SetCredentials(accessKeyID, secretAccessKey, sessionToken string) {
+	c.SetCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, sessionToken))
+}
+
+// SetCredentialsProvider replaces the provider used to sign new requests.
+// Cached presigned URLs are dropped, since they were signed with the old
+// credentials.
+func (c *Client) SetCredentialsProvider(p aws.CredentialsProvider) {
+	c.creds.swap(p)
+	if c.presignCache != nil {
+		c.presignCache.clear()
+	}
+}
@@ -0,0 +1,139 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type DeleteProgress struct {
+	Objects          int64
+	AbortedMultipart int64
+}
+
+// ForceDeleteBucket aborts incomplete multipart uploads, deletes every
+// object version and delete marker (or every object on backends without
+// versioning support) and then deletes the bucket. progress, if non-nil,
+// is called after each batch.
+func (c *Client) ForceDeleteBucket(ctx context.Context, name string, progress func(DeleteProgress)) error {
+	var p DeleteProgress
+	report := func() {
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	uploads := s3.NewListMultipartUploadsPaginator(c.s3Client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(name),
+	})
+	for uploads.HasMorePages() {
+		page, err := uploads.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, u := range page.Uploads {
+			if _, err := c.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(name),
+				Key:      u.Key,
+				UploadId: u.UploadId,
+			}); err != nil && !isNotFound(err) {
+				return err
+			}
+			p.AbortedMultipart++
+		}
+		report()
+	}
+
+	versions := s3.NewListObjectVersionsPaginator(c.s3Client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(name),
+	})
+	for versions.HasMorePages() {
+		page, err := versions.NextPage(ctx)
+		if err != nil {
+			if isNotImplemented(err) {
+				n, err := c.DeletePrefix(ctx, name, "", ListOptions{})
+				p.Objects += n
+				report()
+				if err != nil {
+					return err
+				}
+				break
+			}
+			return err
+		}
+		var ids []types.ObjectIdentifier
+		for _, v := range page.Versions {
+			ids = append(ids, types.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, m := range page.DeleteMarkers {
+			ids = append(ids, types.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+		}
+		if err := c.deleteIdentifiers(ctx, name, ids); err != nil {
+			return err
+		}
+		p.Objects += int64(len(ids))
+		report()
+	}
+
+	return c.DeleteBucket(ctx, name)
+}
+
+// deleteIdentifiers deletes ids in batches of at most 1000. Keys the
+// response reports as throttled are retried with backoff; other per-key
+// failures are turned into an error.
+func (c *Client) deleteIdentifiers(ctx context.Context, bucket string, ids []types.ObjectIdentifier) error {
+	for len(ids) > 0 {
+		n := min(len(ids), maxDeleteBatch)
+		if err := c.deleteBatch(ctx, bucket, ids[:n]); err != nil {
+			return err
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+func (c *Client) deleteBatch(ctx context.Context, bucket string, ids []types.ObjectIdentifier) error {
+	for attempt := 0; ; attempt++ {
+		var output *s3.DeleteObjectsOutput
+		err := c.retryThrottled(ctx, func() (err error) {
+			output, err = c.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+				Bucket: aws.String(bucket),
+				Delete: &types.Delete{Objects: ids, Quiet: aws.Bool(true)},
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if len(output.Errors) == 0 {
+			return nil
+		}
+
+		var retry []types.ObjectIdentifier
+		for _, e := range output.Errors {
+			if !retryableDeleteCode(aws.ToString(e.Code)) {
+				return fmt.Errorf("s3client: failed to delete %d objects, first %s: %s %s",
+					len(output.Errors), aws.ToString(e.Key), aws.ToString(e.Code), aws.ToString(e.Message))
+			}
+			retry = append(retry, types.ObjectIdentifier{Key: e.Key, VersionId: e.VersionId})
+		}
+		if attempt >= throttleRetries {
+			return fmt.Errorf("s3client: %d objects still throttled after %d retries", len(retry), attempt)
+		}
+		if err := c.backoff(ctx, attempt); err != nil {
+			return err
+		}
+		ids = retry
+	}
+}
+
+func retryableDeleteCode(code string) bool {
+	switch code {
+	case "SlowDown", "InternalError", "ServiceUnavailable", "RequestTimeout":
+		return true
+	}
+	return false
+}
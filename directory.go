@@ -0,0 +1,274 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// User metadata written by UploadDirectory when PreserveMetadata is set.
+const (
+	MetaFileMode      = "file-mode"
+	MetaFileMtime     = "file-mtime"
+	MetaSymlinkTarget = "symlink-target"
+)
+
+type SymlinkPolicy int
+
+const (
+	// SymlinkPreserve stores a link as an empty object carrying its target
+	// in MetaSymlinkTarget, and DownloadPrefix recreates the link.
+	SymlinkPreserve SymlinkPolicy = iota
+	// SymlinkFollow uploads what the link points to.
+	SymlinkFollow
+	SymlinkSkip
+)
+
+type DirectoryOptions struct {
+	Concurrency      int
+	PreserveMetadata bool
+	Symlinks         SymlinkPolicy
+}
+
+func (o DirectoryOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return 8
+	}
+	return o.Concurrency
+}
+
+// UploadDirectory uploads every file below dir to prefix, using slash
+// separated relative paths as keys. It attempts every file and returns
+// the failures, including files and directories it could not read, joined
+// into one error.
+func (c *Client) UploadDirectory(ctx context.Context, bucket, prefix, dir string, opts DirectoryOptions, callOpts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, callOpts)
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, opts.concurrency())
+	)
+	fail := func(name string, err error) {
+		mu.Lock()
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		mu.Unlock()
+	}
+	visited := map[string]bool{}
+	var walk func(root, keyPrefix string) error
+	walk = func(root, keyPrefix string) error {
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			if visited[real] {
+				return nil
+			}
+			visited[real] = true
+		}
+		return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			// Unreadable entries are failures like the uploads; the walk
+			// goes on with the rest of the tree.
+			if err != nil {
+				fail(p, err)
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil || rel == "." {
+				return err
+			}
+			key := keyPrefix + filepath.ToSlash(rel)
+			if d.IsDir() {
+				return nil
+			}
+			var metadata map[string]string
+			if d.Type()&fs.ModeSymlink != 0 {
+				switch opts.Symlinks {
+				case SymlinkSkip:
+					return nil
+				case SymlinkPreserve:
+					target, err := os.Readlink(p)
+					if err != nil {
+						fail(p, err)
+						return nil
+					}
+					sem <- struct{}{}
+					wg.Add(1)
+					go func() {
+						defer func() { <-sem; wg.Done() }()
+						if err := c.putSymlink(ctx, bucket, key, target); err != nil {
+							fail(p, err)
+						}
+					}()
+					return nil
+				}
+				fi, err := os.Stat(p)
+				if err != nil {
+					fail(p, err)
+					return nil
+				}
+				if fi.IsDir() {
+					return walk(p, key+"/")
+				}
+				if opts.PreserveMetadata {
+					metadata = fileMetadata(fi)
+				}
+			} else if !d.Type().IsRegular() {
+				return nil
+			} else if opts.PreserveMetadata {
+				fi, err := d.Info()
+				if err != nil {
+					fail(p, err)
+					return nil
+				}
+				metadata = fileMetadata(fi)
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				if err := c.uploadFile(ctx, bucket, key, p, metadata, o); err != nil {
+					fail(p, err)
+				}
+			}()
+			return nil
+		})
+	}
+	err := walk(dir, prefix)
+	wg.Wait()
+	return errors.Join(append(errs, err)...)
+}
+
+func fileMetadata(fi fs.FileInfo) map[string]string {
+	return map[string]string{
+		MetaFileMode:  strconv.FormatUint(uint64(fi.Mode().Perm()), 8),
+		MetaFileMtime: fi.ModTime().UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func (c *Client) putSymlink(ctx context.Context, bucket, key, target string) error {
+	c.invalidate(bucket, key)
+	_, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Body:     strings.NewReader(""),
+		Metadata: map[string]string{MetaSymlinkTarget: target},
+	})
+	return err
+}
+
+// DownloadPrefix downloads every object under prefix into dir. With
+// PreserveMetadata it restores modes, modification times and symlinks
+// recorded by UploadDirectory; links are created after all files so a
+// link cannot redirect a later download. SymlinkSkip leaves them out.
+func (c *Client) DownloadPrefix(ctx context.Context, bucket, prefix, dir string, opts DirectoryOptions, callOpts ...CallOption) error {
+	var objects []types.Object
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		if !strings.HasSuffix(aws.ToString(obj.Key), "/") {
+			objects = append(objects, obj)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var (
+		mu    sync.Mutex
+		errs  []error
+		links = map[string]string{}
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, opts.concurrency())
+	)
+	fail := func(name string, err error) {
+		mu.Lock()
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		mu.Unlock()
+	}
+	for _, obj := range objects {
+		key := aws.ToString(obj.Key)
+		rel := filepath.FromSlash(strings.TrimPrefix(key, prefix))
+		if !filepath.IsLocal(rel) {
+			fail(key, fmt.Errorf("s3client: unsafe key %q", key))
+			continue
+		}
+		localPath := filepath.Join(dir, rel)
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			var meta map[string]string
+			if opts.PreserveMetadata {
+				info, err := c.StatObject(ctx, bucket, key)
+				if err != nil {
+					fail(key, err)
+					return
+				}
+				meta = info.Metadata
+				if target, ok := meta[MetaSymlinkTarget]; ok {
+					if opts.Symlinks != SymlinkSkip {
+						mu.Lock()
+						links[localPath] = target
+						mu.Unlock()
+					}
+					return
+				}
+			}
+			if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+				fail(key, err)
+				return
+			}
+			if err := c.DownloadFile(ctx, bucket, key, localPath, callOpts...); err != nil {
+				fail(key, err)
+				return
+			}
+			if err := restoreFileMetadata(localPath, meta); err != nil {
+				fail(key, err)
+			}
+		}()
+	}
+	wg.Wait()
+	for localPath, target := range links {
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			fail(localPath, err)
+			continue
+		}
+		os.Remove(localPath)
+		if err := os.Symlink(target, localPath); err != nil {
+			fail(localPath, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func restoreFileMetadata(localPath string, meta map[string]string) error {
+	if s, ok := meta[MetaFileMode]; ok {
+		mode, err := strconv.ParseUint(s, 8, 32)
+		if err != nil {
+			return fmt.Errorf("s3client: invalid %s %q", MetaFileMode, s)
+		}
+		if err := os.Chmod(localPath, fs.FileMode(mode).Perm()); err != nil {
+			return err
+		}
+	}
+	if s, ok := meta[MetaFileMtime]; ok {
+		mtime, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("s3client: invalid %s %q", MetaFileMtime, s)
+		}
+		if err := os.Chtimes(localPath, mtime, mtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package s3client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DiskCacheConfig enables GetObjectCached, which keeps downloaded objects
+// under Dir keyed by bucket, key and ETag and evicts the least recently
+// used files once the directory grows beyond MaxBytes.
+type DiskCacheConfig struct {
+	Dir      string
+	MaxBytes int64
+}
+
+type diskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*diskEntry
+	size    int64
+}
+
+type diskEntry struct {
+	size int64
+	used time.Time
+}
+
+func newDiskCache(cfg DiskCacheConfig) (*diskCache, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	dc := &diskCache{dir: cfg.Dir, maxBytes: cfg.MaxBytes, entries: map[string]*diskEntry{}}
+	files, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".tmp-") {
+			continue
+		}
+		fi, err := f.Info()
+		if err != nil {
+			continue
+		}
+		dc.entries[f.Name()] = &diskEntry{size: fi.Size(), used: fi.ModTime()}
+		dc.size += fi.Size()
+	}
+	return dc, nil
+}
+
+func diskCacheName(bucket, key, etag string) (prefix, name string) {
+	sum := sha256.Sum256([]byte(bucket + "\x00" + key))
+	prefix = hex.EncodeToString(sum[:]) + "-"
+	tag := sha256.Sum256([]byte(etag))
+	return prefix, prefix + hex.EncodeToString(tag[:8])
+}
+
+func (dc *diskCache) open(name string) (*os.File, bool) {
+	dc.mu.Lock()
+	e, ok := dc.entries[name]
+	if ok {
+		e.used = time.Now()
+	}
+	dc.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	p := filepath.Join(dc.dir, name)
+	f, err := os.Open(p)
+	if err != nil {
+		dc.forget(name)
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return f, true
+}
+
+func (dc *diskCache) add(prefix, name string, size int64) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for n, e := range dc.entries {
+		if n != name && strings.HasPrefix(n, prefix) {
+			dc.evict(n, e)
+		}
+	}
+	if old, ok := dc.entries[name]; ok {
+		dc.size -= old.size
+	}
+	dc.entries[name] = &diskEntry{size: size, used: time.Now()}
+	dc.size += size
+	if dc.maxBytes <= 0 || dc.size <= dc.maxBytes {
+		return
+	}
+
+	names := make([]string, 0, len(dc.entries))
+	for n := range dc.entries {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return dc.entries[names[i]].used.Before(dc.entries[names[j]].used)
+	})
+	for _, n := range names {
+		if dc.size <= dc.maxBytes || n == name {
+			break
+		}
+		dc.evict(n, dc.entries[n])
+	}
+}
+
+func (dc *diskCache) evict(name string, e *diskEntry) {
+	_ = os.Remove(filepath.Join(dc.dir, name))
+	delete(dc.entries, name)
+	dc.size -= e.size
+}
+
+func (dc *diskCache) forget(name string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if e, ok := dc.entries[name]; ok {
+		delete(dc.entries, name)
+		dc.size -= e.size
+	}
+}
+
+// GetObjectCached returns the object as a local file from the disk cache,
+// downloading it first when no copy with the current ETag is present. The
+// caller must close the file.
+func (c *Client) GetObjectCached(ctx context.Context, bucket, key string) (*os.File, ObjectInfo, error) {
+	if c.diskCache == nil {
+		return nil, ObjectInfo{}, errors.New("s3client: disk cache is not configured")
+	}
+	info, err := c.StatObject(ctx, bucket, key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	prefix, name := diskCacheName(bucket, key, info.ETag)
+	if f, ok := c.diskCache.open(name); ok {
+		return f, info, nil
+	}
+
+	tmp, err := os.CreateTemp(c.diskCache.dir, ".tmp-")
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	n, err := c.downloader.Download(ctx, tmp, &s3.GetObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		IfMatch: aws.String(info.ETag),
+	})
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, ObjectInfo{}, err
+	}
+	dst := filepath.Join(c.diskCache.dir, name)
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		os.Remove(tmp.Name())
+		return nil, ObjectInfo{}, err
+	}
+	c.diskCache.add(prefix, name, n)
+
+	f, err := os.Open(dst)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	return f, info, nil
+}
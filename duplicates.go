@@ -0,0 +1,104 @@
+package s3client
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type DuplicateOptions struct {
+	// Verify compares objects of equal size by hashing their full content
+	// whenever one of them has a multipart ETag, which depends on the part
+	// size rather than only the content. Objects with differing simple
+	// ETags are never grouped.
+	Verify      bool
+	Concurrency int
+}
+
+type DuplicateSet struct {
+	Size int64
+	// ETag is shared by every key, or empty when Verify matched keys with
+	// different ETags.
+	ETag string
+	Keys []string
+}
+
+// Wasted returns the bytes freed by keeping only one copy.
+func (s DuplicateSet) Wasted() int64 {
+	return s.Size * int64(len(s.Keys)-1)
+}
+
+// FindDuplicates groups the objects under prefix with the same size and
+// ETag, largest savings first. Empty objects and directory markers are
+// ignored.
+func (c *Client) FindDuplicates(ctx context.Context, bucket, prefix string, opts DuplicateOptions) ([]DuplicateSet, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = manifestConcurrency
+	}
+	bySize := map[int64][]types.Object{}
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		size := aws.ToInt64(obj.Size)
+		if size > 0 && !strings.HasSuffix(aws.ToString(obj.Key), "/") {
+			bySize[size] = append(bySize[size], obj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []DuplicateSet
+	for size, objs := range bySize {
+		if len(objs) < 2 {
+			continue
+		}
+		verify := opts.Verify && slices.ContainsFunc(objs, func(obj types.Object) bool {
+			return strings.Contains(aws.ToString(obj.ETag), "-")
+		})
+		groupKeys := make([]string, len(objs))
+		if verify {
+			err := forEachConcurrent(len(objs), opts.Concurrency, func(i int) error {
+				sum, err := c.hashObject(ctx, bucket, aws.ToString(objs[i].Key))
+				groupKeys[i] = sum
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			for i, obj := range objs {
+				groupKeys[i] = strings.Trim(aws.ToString(obj.ETag), `"`)
+			}
+		}
+
+		groups := map[string]*DuplicateSet{}
+		for i, obj := range objs {
+			etag := strings.Trim(aws.ToString(obj.ETag), `"`)
+			set, ok := groups[groupKeys[i]]
+			if !ok {
+				set = &DuplicateSet{Size: size, ETag: etag}
+				groups[groupKeys[i]] = set
+			} else if set.ETag != etag {
+				set.ETag = ""
+			}
+			set.Keys = append(set.Keys, aws.ToString(obj.Key))
+		}
+		for _, set := range groups {
+			if len(set.Keys) > 1 {
+				slices.Sort(set.Keys)
+				sets = append(sets, *set)
+			}
+		}
+	}
+	slices.SortFunc(sets, func(a, b DuplicateSet) int {
+		if n := cmp.Compare(b.Wasted(), a.Wasted()); n != 0 {
+			return n
+		}
+		return strings.Compare(a.Keys[0], b.Keys[0])
+	})
+	return sets, nil
+}
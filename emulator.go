@@ -0,0 +1,234 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// EmulatedRule is a lifecycle rule applied by the client for backends that
+// ignore bucket lifecycle configuration. Zero durations disable an action.
+type EmulatedRule struct {
+	Prefix string
+	// ExpireAfter deletes objects last modified longer ago than this.
+	ExpireAfter time.Duration
+	// TransitionAfter moves objects to TransitionPrefix, keeping the part
+	// of the key below Prefix.
+	TransitionAfter  time.Duration
+	TransitionPrefix string
+	// AbortMultipartAfter aborts multipart uploads initiated longer ago.
+	AbortMultipartAfter time.Duration
+}
+
+type LifecycleAction struct {
+	Rule   int
+	Action string // "expire", "transition" or "abort-multipart"
+	Key    string
+	Target string
+	DryRun bool
+}
+
+type LifecycleStats struct {
+	Runs           int64
+	Expired        int64
+	Transitioned   int64
+	AbortedUploads int64
+	Errors         int64
+	LastRun        time.Time
+	LastError      error
+}
+
+type LifecycleEmulatorOptions struct {
+	// Interval between runs started by Start; it defaults to one hour.
+	Interval time.Duration
+	DryRun   bool
+	// OnAction, if set, is called for every action taken or, in dry-run
+	// mode, that would be taken.
+	OnAction func(LifecycleAction)
+}
+
+type LifecycleEmulator struct {
+	c      *Client
+	bucket string
+	rules  []EmulatedRule
+	opts   LifecycleEmulatorOptions
+
+	mu        sync.Mutex
+	stats     LifecycleStats
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *Client) NewLifecycleEmulator(bucket string, rules []EmulatedRule, opts LifecycleEmulatorOptions) *LifecycleEmulator {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Hour
+	}
+	return &LifecycleEmulator{c: c, bucket: bucket, rules: rules, opts: opts}
+}
+
+// Start runs the rules immediately and then every Interval until Stop is
+// called or the client is closed.
+func (e *LifecycleEmulator) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stop != nil {
+		return
+	}
+	e.stop, e.done = make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		defer cancel()
+		go func() {
+			<-stop
+			cancel()
+		}()
+		ticker := time.NewTicker(e.opts.Interval)
+		defer ticker.Stop()
+		for {
+			_ = e.RunOnce(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}(e.stop, e.done)
+	e.closeOnce.Do(func() { e.c.onClose(e.Stop) })
+}
+
+func (e *LifecycleEmulator) Stop() {
+	e.mu.Lock()
+	stop, done := e.stop, e.done
+	e.stop, e.done = nil, nil
+	e.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (e *LifecycleEmulator) Stats() LifecycleStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+// RunOnce applies every rule once. Failures of single objects are counted
+// and joined into the returned error without stopping the run.
+func (e *LifecycleEmulator) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	var errs []error
+	for i, rule := range e.rules {
+		if rule.AbortMultipartAfter > 0 {
+			errs = append(errs, e.abortUploads(ctx, i, rule, now.Add(-rule.AbortMultipartAfter)))
+		}
+		if rule.TransitionAfter > 0 && rule.TransitionPrefix != "" {
+			errs = append(errs, e.transition(ctx, i, rule, now.Add(-rule.TransitionAfter)))
+		}
+		if rule.ExpireAfter > 0 {
+			errs = append(errs, e.expire(ctx, i, rule, now.Add(-rule.ExpireAfter)))
+		}
+	}
+	err := errors.Join(errs...)
+	e.mu.Lock()
+	e.stats.Runs++
+	e.stats.LastRun = now
+	e.stats.LastError = err
+	e.mu.Unlock()
+	return err
+}
+
+func (e *LifecycleEmulator) record(a LifecycleAction, counter *int64, err error) error {
+	e.mu.Lock()
+	if err != nil {
+		e.stats.Errors++
+	} else {
+		*counter++
+	}
+	e.mu.Unlock()
+	if err == nil && e.opts.OnAction != nil {
+		e.opts.OnAction(a)
+	}
+	return err
+}
+
+func (e *LifecycleEmulator) expire(ctx context.Context, i int, rule EmulatedRule, cutoff time.Time) error {
+	report, err := e.c.DeleteOlderThan(ctx, e.bucket, rule.Prefix, rule.ExpireAfter, ExpireOptions{
+		Filter:          ListOptions{ModifiedBefore: cutoff},
+		DryRun:          e.opts.DryRun,
+		ContinueOnError: true,
+	})
+	for _, key := range report.Keys {
+		e.record(LifecycleAction{Rule: i, Action: "expire", Key: key, DryRun: e.opts.DryRun}, &e.stats.Expired, nil)
+	}
+	e.mu.Lock()
+	e.stats.Errors += int64(len(report.Failed))
+	e.mu.Unlock()
+	return err
+}
+
+func (e *LifecycleEmulator) transition(ctx context.Context, i int, rule EmulatedRule, cutoff time.Time) error {
+	var keys []string
+	err := e.c.listFiltered(ctx, e.bucket, rule.Prefix, ListOptions{ModifiedBefore: cutoff}, func(info ObjectInfo) error {
+		// A TransitionPrefix below Prefix holds objects already moved.
+		if !strings.HasPrefix(info.Key, rule.TransitionPrefix) {
+			keys = append(keys, info.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, key := range keys {
+		target := rule.TransitionPrefix + strings.TrimPrefix(key, rule.Prefix)
+		if target == key {
+			continue
+		}
+		var err error
+		if !e.opts.DryRun {
+			err = e.c.MoveObject(ctx, e.bucket, key, target)
+		}
+		errs = append(errs, e.record(LifecycleAction{Rule: i, Action: "transition", Key: key, Target: target, DryRun: e.opts.DryRun}, &e.stats.Transitioned, err))
+	}
+	return errors.Join(errs...)
+}
+
+func (e *LifecycleEmulator) abortUploads(ctx context.Context, i int, rule EmulatedRule, cutoff time.Time) error {
+	paginator := s3.NewListMultipartUploadsPaginator(e.c.s3Client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(e.bucket),
+		Prefix: aws.String(rule.Prefix),
+	})
+	var errs []error
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		for _, u := range page.Uploads {
+			if !aws.ToTime(u.Initiated).Before(cutoff) {
+				continue
+			}
+			var err error
+			if !e.opts.DryRun {
+				_, err = e.c.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+					Bucket:   aws.String(e.bucket),
+					Key:      u.Key,
+					UploadId: u.UploadId,
+				})
+				if isNotFound(err) {
+					err = nil
+				}
+			}
+			errs = append(errs, e.record(LifecycleAction{Rule: i, Action: "abort-multipart", Key: aws.ToString(u.Key), DryRun: e.opts.DryRun}, &e.stats.AbortedUploads, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+type EnsureBucketOptions struct {
+	CreateBucketOptions
+
+	Versioning bool
+	Encryption *types.ServerSideEncryptionConfiguration
+	Lifecycle  []types.LifecycleRule
+}
+
+// EnsureBucket creates the bucket unless it already exists and is owned by
+// the caller, then applies any versioning, encryption and lifecycle
+// settings in opts. It is safe to call concurrently from several
+// instances of the same service.
+func (c *Client) EnsureBucket(ctx context.Context, name string, opts EnsureBucketOptions) error {
+	if err := c.CreateBucketWithOptions(ctx, name, opts.CreateBucketOptions); err != nil {
+		var apiErr smithy.APIError
+		if !errors.As(err, &apiErr) {
+			return err
+		}
+		switch apiErr.ErrorCode() {
+		case "BucketAlreadyOwnedByYou":
+		case "BucketAlreadyExists":
+			// Some backends report this for the caller's own bucket too;
+			// only a bucket we can reach counts as ours.
+			if _, headErr := c.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(name)}); headErr != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+
+	if opts.Versioning {
+		if _, err := c.s3Client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+			Bucket:                  aws.String(name),
+			VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatusEnabled},
+		}); err != nil {
+			return err
+		}
+	}
+	if opts.Encryption != nil {
+		if _, err := c.s3Client.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+			Bucket:                            aws.String(name),
+			ServerSideEncryptionConfiguration: opts.Encryption,
+		}); err != nil {
+			return err
+		}
+	}
+	if len(opts.Lifecycle) > 0 {
+		if _, err := c.s3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 aws.String(name),
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: opts.Lifecycle},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
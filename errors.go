@@ -0,0 +1,140 @@
+package s3client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+// ErrAccessDenied is matched (via errors.Is) by errors for resources that
+// exist but cannot be accessed with the client's credentials, as opposed to
+// resources that do not exist.
+var ErrAccessDenied = errors.New("s3client: access denied")
+
+// ObjectTooLargeError is returned by the byte-oriented helpers when an
+// object exceeds the configured in-memory size limit.
+type ObjectTooLargeError struct {
+	Bucket string
+	Key    string
+	Size   int64
+	Limit  int64
+}
+
+func (e *ObjectTooLargeError) Error() string {
+	return fmt.Sprintf("s3client: object %s/%s is %d bytes, above the in-memory limit of %d; stream it with GetObject or DownloadFile",
+		e.Bucket, e.Key, e.Size, e.Limit)
+}
+
+// OperationError wraps every error returned by an S3 API call with the
+// operation, the bucket and key it targeted and the identifiers AWS support
+// asks for.
+type OperationError struct {
+	Operation  string
+	Bucket     string
+	Key        string
+	StatusCode int
+	RequestID  string
+	HostID     string
+	Err        error
+}
+
+func newOperationError(op string, params any, err error) error {
+	var opErr *OperationError
+	if errors.As(err, &opErr) {
+		return err
+	}
+	e := &OperationError{
+		Operation:  op,
+		Bucket:     inputField(params, "Bucket"),
+		Key:        inputField(params, "Key"),
+		StatusCode: httpStatus(err),
+		Err:        err,
+	}
+	var reqErr interface{ ServiceRequestID() string }
+	if errors.As(err, &reqErr) {
+		e.RequestID = reqErr.ServiceRequestID()
+	}
+	var hostErr interface{ ServiceHostID() string }
+	if errors.As(err, &hostErr) {
+		e.HostID = hostErr.ServiceHostID()
+	}
+	return e
+}
+
+// Error leaves the operation name out: the SDK already prefixes it when it
+// wraps this error in a smithy.OperationError.
+func (e *OperationError) Error() string {
+	target := e.Bucket
+	if e.Key != "" {
+		target += "/" + e.Key
+	}
+	return fmt.Sprintf("s3client: s3://%s: %v", target, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+func httpStatus(err error) int {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode()
+	}
+	return 0
+}
+
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NotFound", "NoSuchKey", "NoSuchBucket":
+			return true
+		}
+	}
+	return httpStatus(err) == http.StatusNotFound
+}
+
+func isForbidden(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "Forbidden", "AccessDenied":
+			return true
+		}
+	}
+	return httpStatus(err) == http.StatusForbidden
+}
+
+// existsResult maps a HEAD error onto the (exists, err) pair returned by
+// BucketExists and ObjectExists.
+func existsResult(err error) (bool, error) {
+	switch {
+	case err == nil:
+		return true, nil
+	case isNotFound(err):
+		return false, nil
+	case isForbidden(err):
+		return false, fmt.Errorf("%w: %w", ErrAccessDenied, err)
+	}
+	return false, err
+}
+
+// isNotImplemented also matches operations a provider profile rejects
+// before sending them.
+func isNotImplemented(err error) bool {
+	if errors.Is(err, ErrNotSupported) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "NotImplemented", "XNotImplemented":
+		return true
+	}
+	return false
+}
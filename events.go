@@ -0,0 +1,294 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// BucketEvent is one S3 event notification record.
+type BucketEvent struct {
+	Name      string    `json:"name"`
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`
+	Region    string    `json:"region,omitempty"`
+	Bucket    string    `json:"bucket"`
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	ETag      string    `json:"etag,omitempty"`
+	VersionID string    `json:"version_id,omitempty"`
+	// Record is the notification record as received.
+	Record json.RawMessage `json:"record"`
+}
+
+// EventSource delivers bucket events. Next blocks until events arrive; ack
+// removes them from the source once handled and may be nil for sources
+// without acknowledgement.
+type EventSource interface {
+	Next(ctx context.Context) (events []BucketEvent, ack func(context.Context) error, err error)
+}
+
+type eventRecord struct {
+	EventSource string    `json:"eventSource"`
+	AWSRegion   string    `json:"awsRegion"`
+	EventTime   time.Time `json:"eventTime"`
+	EventName   string    `json:"eventName"`
+	S3          struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key       string `json:"key"`
+			Size      int64  `json:"size"`
+			ETag      string `json:"eTag"`
+			VersionID string `json:"versionId"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+func parseEventRecords(records []json.RawMessage) ([]BucketEvent, error) {
+	events := make([]BucketEvent, 0, len(records))
+	for _, raw := range records {
+		var r eventRecord
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return nil, fmt.Errorf("s3client: malformed event record: %w", err)
+		}
+		key, err := url.QueryUnescape(r.S3.Object.Key)
+		if err != nil {
+			key = r.S3.Object.Key
+		}
+		events = append(events, BucketEvent{
+			Name:      strings.TrimPrefix(r.EventName, "s3:"),
+			Time:      r.EventTime,
+			Source:    r.EventSource,
+			Region:    r.AWSRegion,
+			Bucket:    r.S3.Bucket.Name,
+			Key:       key,
+			Size:      r.S3.Object.Size,
+			ETag:      r.S3.Object.ETag,
+			VersionID: r.S3.Object.VersionID,
+			Record:    raw,
+		})
+	}
+	return events, nil
+}
+
+// sendSigned signs req with the client credentials for service and sends
+// it through the client's HTTP client.
+func (c *Client) sendSigned(ctx context.Context, req *http.Request, body []byte, service, region string) (*http.Response, error) {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if service == "s3" {
+		setDefaultHeaders(req.Header, c.cfg.DefaultHeaders)
+	}
+	creds, err := c.creds.cache.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, payloadHash, service, region, time.Now()); err != nil {
+		return nil, err
+	}
+	return c.s3Client.Options().HTTPClient.Do(req)
+}
+
+type bucketListener struct {
+	c      *Client
+	bucket string
+	query  url.Values
+
+	mu   sync.Mutex
+	body io.ReadCloser
+	dec  *json.Decoder
+}
+
+// ListenBucketEvents returns a source of the events MinIO streams for
+// bucket through its ListenBucketNotification extension. Events default to
+// s3:ObjectCreated:*. The stream has no acknowledgement: events that occur
+// while disconnected are lost, as are events a consumer fails to handle
+// after Next returned them, and Next reconnects after returning an error.
+// Use SQSEventSource where events must not be lost.
+func (c *Client) ListenBucketEvents(bucket, prefix, suffix string, events ...string) EventSource {
+	if len(events) == 0 {
+		events = []string{"s3:ObjectCreated:*"}
+	}
+	query := url.Values{"events": events, "ping": {"10"}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if suffix != "" {
+		query.Set("suffix", suffix)
+	}
+	return &bucketListener{c: c, bucket: bucket, query: query}
+}
+
+func (l *bucketListener) Next(ctx context.Context) ([]BucketEvent, func(context.Context) error, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dec == nil {
+		if err := l.connect(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Unblock the decoder when ctx ends. The body is captured here, as Next
+	// replaces l.body while the callback may still run.
+	body := l.body
+	stop := context.AfterFunc(ctx, func() { body.Close() })
+	defer stop()
+	for {
+		var msg struct {
+			Records []json.RawMessage `json:"Records"`
+		}
+		if err := l.dec.Decode(&msg); err != nil {
+			l.body.Close()
+			l.body, l.dec = nil, nil
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			return nil, nil, fmt.Errorf("s3client: event stream: %w", err)
+		}
+		// Pings arrive as empty objects or whitespace.
+		if len(msg.Records) > 0 {
+			events, err := parseEventRecords(msg.Records)
+			return events, nil, err
+		}
+	}
+}
+
+func (l *bucketListener) connect(ctx context.Context) error {
+	if l.c.cfg.Endpoint == "" {
+		return errors.New("s3client: ListenBucketEvents needs Config.Endpoint")
+	}
+	u, err := url.Parse(strings.TrimSuffix(l.c.cfg.Endpoint, "/") + "/" + l.bucket)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = l.query.Encode()
+	// The stream outlives this call; it is closed on errors and by Next.
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := l.c.sendSigned(ctx, req, nil, "s3", l.c.cfg.Region)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("s3client: listen on %s: HTTP %d: %s", l.bucket, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	l.body, l.dec = resp.Body, json.NewDecoder(resp.Body)
+	return nil
+}
+
+type sqsSource struct {
+	client   *sqs.Client
+	queueURL string
+}
+
+// SQSEventSource returns a source that long-polls the SQS queue bucket
+// notifications are delivered to, directly or through SNS. Messages are
+// deleted when acknowledged and redelivered by SQS otherwise. Requests go
+// to the host of queueURL.
+func (c *Client) SQSEventSource(queueURL string) EventSource {
+	region := c.cfg.Region
+	var endpoint string
+	if u, err := url.Parse(queueURL); err == nil {
+		// sqs.<region>.amazonaws.com
+		if parts := strings.Split(u.Hostname(), "."); len(parts) >= 4 && parts[0] == "sqs" {
+			region = parts[1]
+		}
+		if u.Scheme != "" && u.Host != "" {
+			endpoint = u.Scheme + "://" + u.Host
+		}
+	}
+	s3Opts := c.s3Client.Options()
+	client := sqs.New(sqs.Options{
+		Region:      region,
+		Credentials: c.creds.cache,
+		HTTPClient:  s3Opts.HTTPClient,
+		Logger:      s3Opts.Logger,
+	}, func(o *sqs.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return &sqsSource{client: client, queueURL: queueURL}
+}
+
+func (s *sqsSource) Next(ctx context.Context) ([]BucketEvent, func(context.Context) error, error) {
+	for {
+		out, err := s.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.queueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     20,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(out.Messages) == 0 {
+			continue
+		}
+		var (
+			events  []BucketEvent
+			entries []sqstypes.DeleteMessageBatchRequestEntry
+		)
+		for i, m := range out.Messages {
+			entries = append(entries, sqstypes.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: m.ReceiptHandle,
+			})
+			body := aws.ToString(m.Body)
+			var envelope struct {
+				Type    string `json:"Type"`
+				Message string `json:"Message"`
+			}
+			if json.Unmarshal([]byte(body), &envelope) == nil && envelope.Type == "Notification" {
+				body = envelope.Message
+			}
+			// Test events and other messages without records are acked.
+			var msg struct {
+				Records []json.RawMessage `json:"Records"`
+			}
+			if err := json.Unmarshal([]byte(body), &msg); err != nil {
+				continue
+			}
+			parsed, err := parseEventRecords(msg.Records)
+			if err != nil {
+				return nil, nil, err
+			}
+			events = append(events, parsed...)
+		}
+		ack := func(ctx context.Context) error {
+			out, err := s.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+				QueueUrl: aws.String(s.queueURL),
+				Entries:  entries,
+			})
+			if err != nil {
+				return err
+			}
+			if len(out.Failed) > 0 {
+				return fmt.Errorf("s3client: failed to delete %d SQS messages: %s", len(out.Failed), aws.ToString(out.Failed[0].Message))
+			}
+			return nil
+		}
+		return events, ack, nil
+	}
+}
@@ -0,0 +1,93 @@
+package s3client
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV       ExportFormat = "csv"
+	ExportJSONLines ExportFormat = "jsonl"
+)
+
+type exportRecord struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+	URL          string    `json:"url,omitempty"`
+}
+
+// ExportListing writes one record per object under prefix to w while
+// paging through the listing. When presignExpiry is positive each record
+// also carries a presigned GET URL valid for that long. It returns the
+// number of records written.
+func (c *Client) ExportListing(ctx context.Context, bucket, prefix string, w io.Writer, format ExportFormat, presignExpiry time.Duration) (int64, error) {
+	buf := bufio.NewWriter(w)
+	var write func(exportRecord) error
+	switch format {
+	case ExportCSV, "":
+		cw := csv.NewWriter(buf)
+		header := []string{"key", "size", "etag", "last_modified"}
+		if presignExpiry > 0 {
+			header = append(header, "url")
+		}
+		if err := cw.Write(header); err != nil {
+			return 0, err
+		}
+		write = func(r exportRecord) error {
+			row := []string{r.Key, strconv.FormatInt(r.Size, 10), r.ETag, r.LastModified.UTC().Format(time.RFC3339)}
+			if presignExpiry > 0 {
+				row = append(row, r.URL)
+			}
+			cw.Write(row)
+			cw.Flush()
+			return cw.Error()
+		}
+	case ExportJSONLines:
+		enc := json.NewEncoder(buf)
+		write = func(r exportRecord) error {
+			return enc.Encode(r)
+		}
+	default:
+		return 0, fmt.Errorf("s3client: unknown export format %q", format)
+	}
+
+	presigner := s3.NewPresignClient(c.s3Client)
+	var n int64
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		info := objectInfo(obj)
+		r := exportRecord{Key: info.Key, Size: info.Size, ETag: info.ETag, LastModified: info.LastModified}
+		if presignExpiry > 0 {
+			req, err := presigner.PresignGetObject(withPresignAudit(ctx), &s3.GetObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(info.Key),
+			}, s3.WithPresignExpires(presignExpiry))
+			if err != nil {
+				return err
+			}
+			r.URL = req.URL
+		}
+		if err := write(r); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	if ferr := buf.Flush(); err == nil {
+		err = ferr
+	}
+	return n, err
+}
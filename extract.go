@@ -0,0 +1,139 @@
+package s3client
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/mkchar/s3client/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	magicZip  = []byte("PK\x03\x04")
+	magicGzip = []byte{0x1f, 0x8b}
+	magicZstd = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ExtractArchive expands a tar (plain, gzip or zstd) or zip object into
+// individual objects under destPrefix and returns the keys it wrote.
+// The archive format is detected from its leading bytes.
+func (c *Client) ExtractArchive(ctx context.Context, bucket, archiveKey, destPrefix string) ([]string, error) {
+	output, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(archiveKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	br := bufio.NewReader(output.Body)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, magicZip):
+		output.Body.Close()
+		ra := newObjectReaderAt(ctx, c, bucket, archiveKey, aws.ToInt64(output.ContentLength), aws.ToString(output.ETag))
+		return c.extractZip(ctx, bucket, ra, ra.size, destPrefix)
+	case bytes.HasPrefix(magic, magicGzip):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return c.extractTar(ctx, bucket, zr, destPrefix)
+	case bytes.HasPrefix(magic, magicZstd):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return c.extractTar(ctx, bucket, zr, destPrefix)
+	}
+	return c.extractTar(ctx, bucket, br, destPrefix)
+}
+
+func (c *Client) extractTar(ctx context.Context, bucket string, r io.Reader, destPrefix string) ([]string, error) {
+	var keys []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return keys, nil
+		}
+		if err != nil {
+			return keys, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		key, err := archiveEntryKey(destPrefix, hdr.Name)
+		if err != nil {
+			return keys, err
+		}
+		if err := c.uploadEntry(ctx, bucket, key, tr); err != nil {
+			return keys, err
+		}
+		keys = append(keys, key)
+	}
+}
+
+func (c *Client) extractZip(ctx context.Context, bucket string, ra io.ReaderAt, size int64, destPrefix string) ([]string, error) {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		key, err := archiveEntryKey(destPrefix, f.Name)
+		if err != nil {
+			return keys, err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return keys, err
+		}
+		err = c.uploadEntry(ctx, bucket, key, rc)
+		rc.Close()
+		if err != nil {
+			return keys, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (c *Client) uploadEntry(ctx context.Context, bucket, key string, body io.Reader) error {
+	_, err := c.upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(utils.DetectContentType(key)),
+	})
+	return err
+}
+
+// archiveEntryKey rejects entries that would escape destPrefix.
+func archiveEntryKey(destPrefix, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	clean := path.Clean("/" + name)
+	if clean == "/" || slices.Contains(strings.Split(name, "/"), "..") {
+		return "", fmt.Errorf("s3client: unsafe archive entry %q", name)
+	}
+	return destPrefix + strings.TrimPrefix(clean, "/"), nil
+}
@@ -0,0 +1,236 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ListOptions controls listing requests and filters their results
+// client-side while paging. Zero values disable the corresponding setting.
+type ListOptions struct {
+	MaxKeys           int32
+	StartAfter        string
+	ContinuationToken string
+	Delimiter         string
+
+	MinSize        int64
+	MaxSize        int64
+	ModifiedBefore time.Time
+	ModifiedAfter  time.Time
+	Suffix         string
+	Regexp         *regexp.Regexp
+
+	// Prefetch, when positive, makes ListObjectsDetailed fill in the
+	// content type, encoding and user metadata of every object with that
+	// many concurrent HeadObject requests.
+	Prefetch int
+}
+
+func (o ListOptions) match(info ObjectInfo) bool {
+	switch {
+	case o.MinSize > 0 && info.Size < o.MinSize:
+		return false
+	case o.MaxSize > 0 && info.Size > o.MaxSize:
+		return false
+	case !o.ModifiedBefore.IsZero() && !info.LastModified.Before(o.ModifiedBefore):
+		return false
+	case !o.ModifiedAfter.IsZero() && !info.LastModified.After(o.ModifiedAfter):
+		return false
+	case o.Suffix != "" && !strings.HasSuffix(info.Key, o.Suffix):
+		return false
+	case o.Regexp != nil && !o.Regexp.MatchString(info.Key):
+		return false
+	}
+	return true
+}
+
+func (o ListOptions) input(bucket, prefix string) *s3.ListObjectsV2Input {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	if o.MaxKeys > 0 {
+		input.MaxKeys = aws.Int32(o.MaxKeys)
+	}
+	if o.StartAfter != "" {
+		input.StartAfter = aws.String(o.StartAfter)
+	}
+	if o.ContinuationToken != "" {
+		input.ContinuationToken = aws.String(o.ContinuationToken)
+	}
+	if o.Delimiter != "" {
+		input.Delimiter = aws.String(o.Delimiter)
+	}
+	return input
+}
+
+func (c *Client) listFiltered(ctx context.Context, bucket, prefix string, opts ListOptions, fn func(ObjectInfo) error) error {
+	return c.listPages(ctx, opts.input(bucket, prefix), func(page *s3.ListObjectsV2Output) error {
+		for _, obj := range page.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			info := objectInfo(obj)
+			if !opts.match(info) {
+				continue
+			}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *Client) ListObjectsDetailed(ctx context.Context, bucket, prefix string, opts ListOptions) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := c.listFiltered(ctx, bucket, prefix, opts, func(info ObjectInfo) error {
+		objects = append(objects, info)
+		return nil
+	})
+	if err == nil && opts.Prefetch > 0 {
+		err = c.prefetch(ctx, bucket, objects, opts.Prefetch)
+	}
+	return objects, err
+}
+
+// DeletePrefix deletes the objects under prefix that pass opts, one
+// DeleteObjects batch per listing page, and returns how many were deleted.
+func (c *Client) DeletePrefix(ctx context.Context, bucket, prefix string, opts ListOptions) (int64, error) {
+	return c.deletePrefix(ctx, bucket, prefix, opts, nil)
+}
+
+// DeletePrefixResumable is DeletePrefix recording the last deleted key in
+// cp after every batch. A later call with the same checkpoint continues
+// after that key, and the checkpoint is removed once everything is deleted.
+func (c *Client) DeletePrefixResumable(ctx context.Context, bucket, prefix string, opts ListOptions, cp Checkpoint) (int64, error) {
+	id := cp.id(bucket, prefix)
+	last, err := cp.Store.Load(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if last > opts.StartAfter {
+		opts.StartAfter = last
+		opts.ContinuationToken = ""
+	}
+	deleted, err := c.deletePrefix(ctx, bucket, prefix, opts, func(key string) error {
+		return cp.Store.Save(ctx, id, key)
+	})
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, cp.Store.Delete(ctx, id)
+}
+
+func (c *Client) deletePrefix(ctx context.Context, bucket, prefix string, opts ListOptions, deletedUpTo func(key string) error) (int64, error) {
+	var (
+		deleted int64
+		batch   []string
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := c.DeleteObjects(ctx, bucket, batch); err != nil {
+			return err
+		}
+		deleted += int64(len(batch))
+		if deletedUpTo != nil {
+			if err := deletedUpTo(batch[len(batch)-1]); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err := c.listFiltered(ctx, bucket, prefix, opts, func(info ObjectInfo) error {
+		batch = append(batch, info.Key)
+		if len(batch) == maxDeleteBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, flush()
+}
+
+const maxDeleteBatch = 1000
+
+type ExpireOptions struct {
+	// Filter further restricts the objects considered. ModifiedBefore is
+	// replaced by the age cutoff when that is earlier.
+	Filter ListOptions
+	// DryRun lists what would be deleted without deleting it.
+	DryRun bool
+	// ContinueOnError keeps deleting after a batch fails. The keys of
+	// failed batches are reported in Failed and the errors joined.
+	ContinueOnError bool
+}
+
+// ExpireReport lists the deleted keys, or with DryRun those that would be.
+type ExpireReport struct {
+	Keys   []string
+	Bytes  int64
+	Failed []string
+}
+
+// DeleteOlderThan deletes the objects under prefix last modified more than
+// age ago, in batches as the listing is paged, for backends without
+// lifecycle expiration. On error the report covers the batches already
+// deleted.
+func (c *Client) DeleteOlderThan(ctx context.Context, bucket, prefix string, age time.Duration, opts ExpireOptions) (ExpireReport, error) {
+	filter := opts.Filter
+	if cutoff := time.Now().Add(-age); filter.ModifiedBefore.IsZero() || cutoff.Before(filter.ModifiedBefore) {
+		filter.ModifiedBefore = cutoff
+	}
+	var (
+		report ExpireReport
+		batch  []ObjectInfo
+		errs   []error
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !opts.DryRun {
+			keys := make([]string, len(batch))
+			for i, info := range batch {
+				keys[i] = info.Key
+			}
+			if err := c.DeleteObjects(ctx, bucket, keys); err != nil {
+				if !opts.ContinueOnError || ctx.Err() != nil {
+					return err
+				}
+				errs = append(errs, err)
+				report.Failed = append(report.Failed, keys...)
+				batch = batch[:0]
+				return nil
+			}
+		}
+		for _, info := range batch {
+			report.Keys = append(report.Keys, info.Key)
+			report.Bytes += info.Size
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err := c.listFiltered(ctx, bucket, prefix, filter, func(info ObjectInfo) error {
+		batch = append(batch, info)
+		if len(batch) == maxDeleteBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	return report, errors.Join(append(errs, err)...)
+}
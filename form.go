@@ -0,0 +1,139 @@
+package s3client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/mkchar/s3client/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type FormUploadOptions struct {
+	// MaxFileSize and MaxFiles bound each part and the number of files;
+	// zero means no limit.
+	MaxFileSize int64
+	MaxFiles    int
+	// KeyFunc maps a form field and client file name to the key below
+	// keyPrefix. It defaults to the sanitized base name of the file.
+	KeyFunc func(field, filename string) string
+}
+
+type FormFile struct {
+	Field       string
+	FileName    string
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+var ErrFormFileTooLarge = errors.New("s3client: form file too large")
+
+// UploadFromHTTPRequest streams every file part of a multipart/form-data
+// request to keyPrefix without buffering it to disk. Other form fields are
+// skipped. Files whose name maps to an empty key, or to the key of an
+// earlier file in the request, fail the request. On error the files stored
+// so far are returned with it.
+func (c *Client) UploadFromHTTPRequest(ctx context.Context, bucket, keyPrefix string, r *http.Request, opts FormUploadOptions) ([]FormFile, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	var files []FormFile
+	seen := map[string]bool{}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		if opts.MaxFiles > 0 && len(files) == opts.MaxFiles {
+			part.Close()
+			return files, fmt.Errorf("s3client: more than %d files in form", opts.MaxFiles)
+		}
+		name := formFileKey(part.FormName(), part.FileName(), opts)
+		if name == "" {
+			part.Close()
+			return files, fmt.Errorf("s3client: form file %q has no usable name", part.FileName())
+		}
+		if seen[name] {
+			part.Close()
+			return files, fmt.Errorf("s3client: form file %q maps to the key %s of an earlier file", part.FileName(), keyPrefix+name)
+		}
+		seen[name] = true
+		file, err := c.uploadFormPart(ctx, bucket, keyPrefix+name, part.FormName(), part.FileName(), part, part.Header.Get("Content-Type"), opts)
+		part.Close()
+		if err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+}
+
+func formFileKey(field, filename string, opts FormUploadOptions) string {
+	if opts.KeyFunc != nil {
+		return opts.KeyFunc(field, filename)
+	}
+	return utils.SanitizeKey(path.Base(strings.ReplaceAll(filename, "\\", "/")))
+}
+
+func (c *Client) uploadFormPart(ctx context.Context, bucket, key, field, filename string, body io.Reader, declared string, opts FormUploadOptions) (FormFile, error) {
+	file := FormFile{Field: field, FileName: filename, Key: key}
+
+	br := bufio.NewReaderSize(body, 512)
+	file.ContentType = utils.DetectContentType(key)
+	if file.ContentType == "application/octet-stream" {
+		if declared != "" && declared != "application/octet-stream" {
+			file.ContentType = declared
+		} else if head, _ := br.Peek(512); len(head) > 0 {
+			file.ContentType = http.DetectContentType(head)
+		}
+	}
+
+	counter := &formCounter{r: br, limit: opts.MaxFileSize}
+	c.invalidate(bucket, file.Key)
+	_, err := c.upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(file.Key),
+		Body:        counter,
+		ContentType: aws.String(file.ContentType),
+	})
+	if err != nil {
+		if counter.exceeded {
+			return FormFile{}, fmt.Errorf("%w: %s is over %d bytes", ErrFormFileTooLarge, filename, opts.MaxFileSize)
+		}
+		return FormFile{}, err
+	}
+	file.Size = counter.n
+	return file, nil
+}
+
+type formCounter struct {
+	r        io.Reader
+	n        int64
+	limit    int64
+	exceeded bool
+}
+
+func (f *formCounter) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	f.n += int64(n)
+	if f.limit > 0 && f.n > f.limit {
+		f.exceeded = true
+		return n, ErrFormFileTooLarge
+	}
+	return n, err
+}
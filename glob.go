@@ -0,0 +1,44 @@
+package s3client
+
+import (
+	"context"
+	"iter"
+	"path"
+	"strings"
+)
+
+// ListObjectsGlob lazily yields the objects whose keys match a shell-style
+// pattern (path.Match syntax, so "*" does not cross "/"). The literal part
+// of the pattern before the first wildcard is used as the listing prefix.
+func (c *Client) ListObjectsGlob(ctx context.Context, bucket, pattern string) iter.Seq2[ObjectInfo, error] {
+	return func(yield func(ObjectInfo, error) bool) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			yield(ObjectInfo{}, err)
+			return
+		}
+		prefix := globPrefix(pattern)
+		depth := strings.Count(pattern, "/")
+		err := c.Walk(ctx, bucket, prefix, func(info ObjectInfo) error {
+			if strings.Count(info.Key, "/") > depth {
+				return SkipPrefix
+			}
+			if ok, _ := path.Match(pattern, info.Key); !ok {
+				return nil
+			}
+			if !yield(info, nil) {
+				return SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			yield(ObjectInfo{}, err)
+		}
+	}
+}
+
+func globPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?[\\"); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
@@ -0,0 +1,32 @@
+package s3client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// newDefaultHeaders adds headers to every request that does not set them
+// already. They are added before signing, so presigned URLs carry them as
+// signed headers.
+func newDefaultHeaders(headers map[string]string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Build.Add(middleware.BuildMiddlewareFunc("s3client.DefaultHeaders",
+			func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+				if req, ok := in.Request.(*smithyhttp.Request); ok {
+					setDefaultHeaders(req.Header, headers)
+				}
+				return next.HandleBuild(ctx, in)
+			}), middleware.After)
+	}
+}
+
+func setDefaultHeaders(h http.Header, headers map[string]string) {
+	for k, v := range headers {
+		if h.Get(k) == "" {
+			h.Set(k, v)
+		}
+	}
+}
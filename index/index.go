@@ -0,0 +1,276 @@
+// Package index keeps a local bbolt index of the objects in a bucket so
+// they can be searched by metadata, tags, size and date.
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkchar/s3client"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	objectsBucket = []byte("objects")
+	stateBucket   = []byte("state")
+	generationKey = []byte("generation")
+)
+
+type Options struct {
+	// Metadata fetches content type and user metadata with a HEAD request
+	// per object; Tags fetches object tags. Both cost one request per key.
+	Metadata    bool
+	Tags        bool
+	Concurrency int
+}
+
+type Entry struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag"`
+	LastModified time.Time         `json:"last_modified"`
+	ContentType  string            `json:"content_type,omitempty"`
+	StorageClass string            `json:"storage_class,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Generation   uint64            `json:"generation"`
+}
+
+type Index struct {
+	c      *s3client.Client
+	bucket string
+	db     *bolt.DB
+	opts   Options
+}
+
+// Open opens or creates the index database at path for bucket.
+func Open(path string, c *s3client.Client, bucket string, opts Options) (*Index, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{objectsBucket, stateBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Index{c: c, bucket: bucket, db: db, opts: opts}, nil
+}
+
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// Crawl indexes every object under prefix and drops index entries under
+// prefix that no longer exist. It returns the number of objects indexed.
+func (ix *Index) Crawl(ctx context.Context, prefix string) (int64, error) {
+	var gen uint64
+	err := ix.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(stateBucket)
+		if v := b.Get(generationKey); v != nil {
+			gen = binary.BigEndian.Uint64(v)
+		}
+		gen++
+		return b.Put(generationKey, binary.BigEndian.AppendUint64(nil, gen))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		mu    sync.Mutex
+		errs  []error
+		count int64
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, ix.opts.Concurrency)
+	)
+	err = ix.c.Walk(ctx, ix.bucket, prefix, func(info s3client.ObjectInfo) error {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			err := ix.index(ctx, info, gen)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", info.Key, err))
+				return
+			}
+			count++
+		}()
+		return nil
+	})
+	wg.Wait()
+	if err != nil || len(errs) > 0 {
+		return count, errors.Join(append(errs, err)...)
+	}
+	return count, ix.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(objectsBucket).Cursor()
+		for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			if e.Generation == gen {
+				k, v = c.Next()
+				continue
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			k, v = c.Seek(k)
+		}
+		return nil
+	})
+}
+
+// Update re-indexes one key, for example in response to a bucket event,
+// and removes it from the index if the object is gone.
+func (ix *Index) Update(ctx context.Context, key string) error {
+	info, err := ix.c.StatObject(ctx, ix.bucket, key)
+	if err != nil {
+		var opErr *s3client.OperationError
+		if errors.As(err, &opErr) && opErr.StatusCode == 404 {
+			return ix.Remove(key)
+		}
+		return err
+	}
+	var gen uint64
+	err = ix.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(stateBucket).Get(generationKey); v != nil {
+			gen = binary.BigEndian.Uint64(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ix.index(ctx, info, gen)
+}
+
+func (ix *Index) Remove(key string) error {
+	return ix.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(objectsBucket).Delete([]byte(key))
+	})
+}
+
+func (ix *Index) index(ctx context.Context, info s3client.ObjectInfo, gen uint64) error {
+	if ix.opts.Metadata && info.Metadata == nil {
+		full, err := ix.c.StatObject(ctx, ix.bucket, info.Key)
+		if err != nil {
+			return err
+		}
+		info.ContentType, info.Metadata = full.ContentType, full.Metadata
+	}
+	e := Entry{
+		Key:          info.Key,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		ContentType:  info.ContentType,
+		StorageClass: info.StorageClass,
+		Metadata:     info.Metadata,
+		Generation:   gen,
+	}
+	if ix.opts.Tags {
+		tags, err := ix.c.GetObjectTags(ctx, ix.bucket, info.Key)
+		if err != nil {
+			return err
+		}
+		e.Tags = tags
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return ix.db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(objectsBucket).Put([]byte(e.Key), data)
+	})
+}
+
+// Query filters index entries; zero fields match everything. Tags and
+// Metadata entries must all match exactly.
+type Query struct {
+	Prefix         string
+	Suffix         string
+	ContentType    string
+	Tags           map[string]string
+	Metadata       map[string]string
+	MinSize        int64
+	MaxSize        int64
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	Limit          int
+}
+
+func (q Query) match(e Entry) bool {
+	if q.Suffix != "" && !strings.HasSuffix(strings.ToLower(e.Key), strings.ToLower(q.Suffix)) {
+		return false
+	}
+	if q.ContentType != "" && !strings.EqualFold(e.ContentType, q.ContentType) {
+		return false
+	}
+	if (q.MinSize > 0 && e.Size < q.MinSize) || (q.MaxSize > 0 && e.Size > q.MaxSize) {
+		return false
+	}
+	if (!q.ModifiedAfter.IsZero() && !e.LastModified.After(q.ModifiedAfter)) ||
+		(!q.ModifiedBefore.IsZero() && !e.LastModified.Before(q.ModifiedBefore)) {
+		return false
+	}
+	for k, v := range q.Tags {
+		if e.Tags[k] != v {
+			return false
+		}
+	}
+	for k, v := range q.Metadata {
+		if e.Metadata[strings.ToLower(k)] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Search returns the entries matching q in key order.
+func (ix *Index) Search(ctx context.Context, q Query) ([]Entry, error) {
+	var entries []Entry
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(objectsBucket).Cursor()
+		prefix := []byte(q.Prefix)
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			if !q.match(e) {
+				continue
+			}
+			entries = append(entries, e)
+			if q.Limit > 0 && len(entries) == q.Limit {
+				return nil
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,212 @@
+package s3client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// InventoryManifest is the manifest.json S3 Inventory writes with each
+// report.
+type InventoryManifest struct {
+	SourceBucket      string          `json:"sourceBucket"`
+	DestinationBucket string          `json:"destinationBucket"`
+	Version           string          `json:"version"`
+	CreationTimestamp string          `json:"creationTimestamp"`
+	FileFormat        string          `json:"fileFormat"`
+	FileSchema        string          `json:"fileSchema"`
+	Files             []InventoryFile `json:"files"`
+}
+
+type InventoryFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5Checksum string `json:"MD5checksum"`
+}
+
+func (c *Client) GetInventoryManifest(ctx context.Context, bucket, manifestKey string) (InventoryManifest, error) {
+	data, err := c.GetObjectBytes(ctx, bucket, manifestKey, WithMaxInMemorySize(0))
+	if err != nil {
+		return InventoryManifest{}, err
+	}
+	var m InventoryManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return InventoryManifest{}, fmt.Errorf("s3client: malformed inventory manifest: %w", err)
+	}
+	return m, nil
+}
+
+// ReadInventory lazily yields the records of the inventory report whose
+// manifest is bucket/manifestKey, reading its data files one at a time.
+// Key is taken from the report and Bucket is dropped. CSV and Parquet
+// reports are supported; ORC reports fail with ErrNotSupported.
+func (c *Client) ReadInventory(ctx context.Context, bucket, manifestKey string) iter.Seq2[ObjectInfo, error] {
+	return func(yield func(ObjectInfo, error) bool) {
+		m, err := c.GetInventoryManifest(ctx, bucket, manifestKey)
+		if err != nil {
+			yield(ObjectInfo{}, err)
+			return
+		}
+		isParquet := strings.EqualFold(m.FileFormat, "Parquet")
+		if !isParquet && !strings.EqualFold(m.FileFormat, "CSV") {
+			yield(ObjectInfo{}, fmt.Errorf("%w: inventory format %s", ErrNotSupported, m.FileFormat))
+			return
+		}
+		dataBucket := bucket
+		if i := strings.LastIndex(m.DestinationBucket, ":"); i >= 0 && i < len(m.DestinationBucket)-1 {
+			dataBucket = m.DestinationBucket[i+1:]
+		}
+		// The schema of Parquet reports is a message definition; their
+		// columns are matched by name instead.
+		var fields []string
+		for _, f := range strings.Split(m.FileSchema, ",") {
+			fields = append(fields, strings.TrimSpace(f))
+		}
+		for _, file := range m.Files {
+			read := c.readInventoryFile
+			if isParquet {
+				read = c.readParquetInventoryFile
+			}
+			if !read(ctx, dataBucket, file.Key, fields, yield) {
+				return
+			}
+		}
+	}
+}
+
+// readInventoryFile reports whether iteration should continue.
+func (c *Client) readInventoryFile(ctx context.Context, bucket, key string, fields []string, yield func(ObjectInfo, error) bool) bool {
+	body, err := c.GetObject(ctx, bucket, key, WithDecompression(false))
+	if err != nil {
+		return yield(ObjectInfo{}, err)
+	}
+	defer body.Close()
+	var r io.Reader = body
+	if strings.HasSuffix(key, ".gz") {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return yield(ObjectInfo{}, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(fields)
+	cr.ReuseRecord = true
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		if err != nil {
+			return yield(ObjectInfo{}, fmt.Errorf("s3client: inventory file %s: %w", key, err))
+		}
+		info, err := inventoryRecord(fields, record)
+		if err != nil {
+			err = fmt.Errorf("s3client: inventory file %s: %w", key, err)
+		}
+		if !yield(info, err) || err != nil {
+			return false
+		}
+	}
+}
+
+func inventoryRecord(fields, record []string) (ObjectInfo, error) {
+	var info ObjectInfo
+	for i, name := range fields {
+		v := record[i]
+		var err error
+		switch name {
+		case "Key":
+			info.Key, err = url.QueryUnescape(v)
+		case "VersionId":
+			info.VersionID = v
+		case "Size":
+			if v != "" {
+				info.Size, err = strconv.ParseInt(v, 10, 64)
+			}
+		case "LastModifiedDate":
+			info.LastModified, err = time.Parse(time.RFC3339, v)
+		case "ETag":
+			info.ETag = v
+		case "StorageClass":
+			info.StorageClass = v
+		}
+		if err != nil {
+			return ObjectInfo{}, fmt.Errorf("field %s: %w", name, err)
+		}
+	}
+	return info, nil
+}
+
+// parquetInventoryRow holds the columns of a Parquet report that map onto
+// ObjectInfo. Keys are not URL-encoded as they are in CSV reports.
+type parquetInventoryRow struct {
+	Key              string `parquet:"key,optional"`
+	VersionID        string `parquet:"version_id,optional"`
+	Size             int64  `parquet:"size,optional"`
+	LastModifiedDate int64  `parquet:"last_modified_date,optional,timestamp(millisecond)"`
+	ETag             string `parquet:"e_tag,optional"`
+	StorageClass     string `parquet:"storage_class,optional"`
+}
+
+// readParquetInventoryFile reads the file with ranged GETs, as Parquet
+// keeps its schema in a footer.
+func (c *Client) readParquetInventoryFile(ctx context.Context, bucket, key string, _ []string, yield func(ObjectInfo, error) bool) bool {
+	info, err := c.StatObject(ctx, bucket, key)
+	if err != nil {
+		return yield(ObjectInfo{}, err)
+	}
+	r := newObjectReaderAt(ctx, c, bucket, key, info.Size, info.ETag)
+	more, err := readParquetInventory(r, info.Size, yield)
+	if err != nil {
+		return yield(ObjectInfo{}, fmt.Errorf("s3client: inventory file %s: %w", key, err))
+	}
+	return more
+}
+
+// readParquetInventory reports whether iteration should continue.
+func readParquetInventory(r io.ReaderAt, size int64, yield func(ObjectInfo, error) bool) (bool, error) {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return false, err
+	}
+	rows := parquet.NewGenericReader[parquetInventoryRow](file)
+	defer rows.Close()
+	buf := make([]parquetInventoryRow, 256)
+	for {
+		n, err := rows.Read(buf)
+		for _, row := range buf[:n] {
+			info := ObjectInfo{
+				Key:          row.Key,
+				VersionID:    row.VersionID,
+				Size:         row.Size,
+				ETag:         row.ETag,
+				StorageClass: row.StorageClass,
+			}
+			if row.LastModifiedDate != 0 {
+				info.LastModified = time.UnixMilli(row.LastModifiedDate).UTC()
+			}
+			if !yield(info, nil) {
+				return false, nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
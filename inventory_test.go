@@ -0,0 +1,64 @@
+package s3client
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// s3InventoryRow mirrors the Parquet schema S3 Inventory writes.
+type s3InventoryRow struct {
+	Bucket           string    `parquet:"bucket"`
+	Key              string    `parquet:"key"`
+	VersionID        string    `parquet:"version_id,optional"`
+	IsLatest         bool      `parquet:"is_latest,optional"`
+	Size             int64     `parquet:"size,optional"`
+	LastModifiedDate time.Time `parquet:"last_modified_date,optional,timestamp(millisecond)"`
+	ETag             string    `parquet:"e_tag,optional"`
+	StorageClass     string    `parquet:"storage_class,optional"`
+}
+
+func TestReadParquetInventory(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	rows := []s3InventoryRow{
+		{Bucket: "b", Key: "dir/a b.txt", Size: 3, LastModifiedDate: modified, ETag: "e1", StorageClass: "STANDARD", IsLatest: true},
+		{Bucket: "b", Key: "dir/c", VersionID: "v2", Size: 0, ETag: "e2", StorageClass: "GLACIER"},
+	}
+	var buf bytes.Buffer
+	if err := parquet.Write(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []ObjectInfo
+	more, err := readParquetInventory(bytes.NewReader(buf.Bytes()), int64(buf.Len()), func(info ObjectInfo, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, info)
+		return true
+	})
+	if err != nil || !more {
+		t.Fatalf("readParquetInventory = %v, %v", more, err)
+	}
+	want := []ObjectInfo{
+		{Key: "dir/a b.txt", Size: 3, LastModified: modified, ETag: "e1", StorageClass: "STANDARD"},
+		{Key: "dir/c", VersionID: "v2", ETag: "e2", StorageClass: "GLACIER"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Key != want[i].Key || got[i].VersionID != want[i].VersionID || got[i].Size != want[i].Size ||
+			!got[i].LastModified.Equal(want[i].LastModified) || got[i].ETag != want[i].ETag || got[i].StorageClass != want[i].StorageClass {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Stopping early is reported without an error.
+	more, err = readParquetInventory(bytes.NewReader(buf.Bytes()), int64(buf.Len()), func(ObjectInfo, error) bool { return false })
+	if err != nil || more {
+		t.Errorf("stopped read = %v, %v", more, err)
+	}
+}
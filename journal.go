@@ -0,0 +1,305 @@
+package s3client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// journalSegmentSuffix marks objects holding compacted entries. A
+	// segment is named after its last entry, so it sorts right after the
+	// entries it replaced.
+	journalSegmentSuffix = ".seg"
+	// journalSegmentEntries caps the entries Compact puts in one segment.
+	journalSegmentEntries = 10000
+	// ulidLen is the length of a ULID in Crockford base32.
+	ulidLen = 26
+)
+
+type JournalOptions struct {
+	// Settle withholds entries younger than this from readers, so entries
+	// of concurrent writers that are still being uploaded are not skipped.
+	// Zero is only safe with a single writer.
+	Settle time.Duration
+	// PollInterval is how often Tail lists new entries; it defaults to one
+	// second.
+	PollInterval time.Duration
+}
+
+type JournalEntry struct {
+	Key  string    `json:"key"`
+	Time time.Time `json:"time"`
+	Data []byte    `json:"data"`
+}
+
+// Journal is an append-only log stored as one object per entry under a
+// prefix. Keys are ULIDs, which sort by creation time, so listing the
+// prefix reads the entries in order.
+type Journal struct {
+	c      *Client
+	bucket string
+	prefix string
+	opts   JournalOptions
+
+	mu       sync.Mutex
+	lastMs   uint64
+	lastRand [10]byte
+}
+
+func (c *Client) NewJournal(bucket, prefix string, opts JournalOptions) *Journal {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	return &Journal{c: c, bucket: bucket, prefix: prefix, opts: opts}
+}
+
+// Append writes data as a new entry and returns its key.
+func (j *Journal) Append(ctx context.Context, data []byte) (string, error) {
+	key := j.prefix + j.nextULID(time.Now())
+	if err := j.c.PutObjectBytes(ctx, j.bucket, key, data, "application/octet-stream"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// ReadJournalSince returns the entries of the journal under prefix whose
+// keys sort after afterKey, in order. An empty afterKey reads from the
+// start.
+func (c *Client) ReadJournalSince(ctx context.Context, bucket, prefix, afterKey string) ([]JournalEntry, error) {
+	return c.NewJournal(bucket, prefix, JournalOptions{}).ReadSince(ctx, afterKey)
+}
+
+func (j *Journal) ReadSince(ctx context.Context, afterKey string) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	err := j.readSince(ctx, afterKey, func(e JournalEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// Tail calls fn for every entry after afterKey, polling for new entries
+// until ctx is done or fn fails. Pass the key of the last handled entry to
+// resume after a restart.
+func (j *Journal) Tail(ctx context.Context, afterKey string, fn func(JournalEntry) error) error {
+	for {
+		err := j.readSince(ctx, afterKey, func(e JournalEntry) error {
+			if err := fn(e); err != nil {
+				return err
+			}
+			afterKey = e.Key
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := sleepCtx(ctx, j.opts.PollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+func (j *Journal) readSince(ctx context.Context, afterKey string, fn func(JournalEntry) error) error {
+	cutoff := time.Now().Add(-j.opts.Settle)
+	last := afterKey
+	emit := func(e JournalEntry) error {
+		// Entries being compacted can be listed both on their own and in
+		// their segment.
+		if e.Key <= last {
+			return nil
+		}
+		last = e.Key
+		return fn(e)
+	}
+	err := j.c.listFiltered(ctx, j.bucket, j.prefix, ListOptions{StartAfter: afterKey}, func(info ObjectInfo) error {
+		name := strings.TrimPrefix(info.Key, j.prefix)
+		if strings.HasSuffix(name, journalSegmentSuffix) {
+			return j.readSegment(ctx, info.Key, emit)
+		}
+		t, ok := parseULIDTime(name)
+		if !ok {
+			return nil
+		}
+		if j.opts.Settle > 0 && !t.Before(cutoff) {
+			return errStopJournal
+		}
+		if info.Key <= last {
+			// Already read from the segment it was compacted into.
+			return nil
+		}
+		data, err := j.c.GetObjectBytes(ctx, j.bucket, info.Key)
+		if isNotFound(err) {
+			return j.readCompacted(ctx, info.Key, emit)
+		}
+		if err != nil {
+			return err
+		}
+		return emit(JournalEntry{Key: info.Key, Time: t, Data: data})
+	})
+	if errors.Is(err, errStopJournal) {
+		return nil
+	}
+	return err
+}
+
+var errStopJournal = errors.New("s3client: stop reading journal")
+
+// readCompacted reads the segment holding an entry Compact deleted after
+// it was listed. Segments cover consecutive entries and are named after
+// their last one, so it is the first segment that sorts after the entry.
+func (j *Journal) readCompacted(ctx context.Context, key string, fn func(JournalEntry) error) error {
+	var segment string
+	err := j.c.listFiltered(ctx, j.bucket, j.prefix, ListOptions{StartAfter: key}, func(info ObjectInfo) error {
+		if !strings.HasSuffix(info.Key, journalSegmentSuffix) {
+			return nil
+		}
+		segment = info.Key
+		return errStopJournal
+	})
+	if err != nil && !errors.Is(err, errStopJournal) {
+		return err
+	}
+	if segment == "" {
+		return nil
+	}
+	return j.readSegment(ctx, segment, fn)
+}
+
+func (j *Journal) readSegment(ctx context.Context, key string, fn func(JournalEntry) error) error {
+	r, err := j.c.NewJSONLinesReader(ctx, j.bucket, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for {
+		var e JournalEntry
+		if err := r.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
+
+// Compact merges the entries written before the given time into segment
+// objects and deletes them, and returns the number of entries compacted.
+// Readers see every entry exactly once while Compact runs: an entry
+// deleted after a reader listed it is read from its segment instead.
+func (j *Journal) Compact(ctx context.Context, before time.Time) (int, error) {
+	var (
+		batch []JournalEntry
+		total int
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		w := j.c.NewJSONLinesWriter(ctx, j.bucket, batch[len(batch)-1].Key+journalSegmentSuffix)
+		for _, e := range batch {
+			if err := w.Encode(e); err != nil {
+				return w.CloseWithError(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		keys := make([]string, len(batch))
+		for i, e := range batch {
+			keys[i] = e.Key
+		}
+		if err := j.c.DeleteObjects(ctx, j.bucket, keys); err != nil {
+			return err
+		}
+		total += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	err := j.c.listFiltered(ctx, j.bucket, j.prefix, ListOptions{}, func(info ObjectInfo) error {
+		t, ok := parseULIDTime(strings.TrimPrefix(info.Key, j.prefix))
+		if !ok {
+			// Existing segments stay as they are.
+			return nil
+		}
+		if !t.Before(before) {
+			return errStopJournal
+		}
+		data, err := j.c.GetObjectBytes(ctx, j.bucket, info.Key)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, JournalEntry{Key: info.Key, Time: t, Data: data})
+		if len(batch) >= journalSegmentEntries {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopJournal) {
+		return total, err
+	}
+	return total, flush()
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// nextULID returns a ULID for t that sorts after every ULID the journal
+// generated before, even within the same millisecond.
+func (j *Journal) nextULID(t time.Time) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ms := uint64(t.UnixMilli())
+	if ms <= j.lastMs {
+		ms = j.lastMs
+		for i := len(j.lastRand) - 1; i >= 0; i-- {
+			j.lastRand[i]++
+			if j.lastRand[i] != 0 {
+				break
+			}
+		}
+	} else {
+		j.lastMs = ms
+		rand.Read(j.lastRand[:])
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], ms<<16)
+	copy(b[6:], j.lastRand[:])
+
+	// 128 bits as 26 base32 digits, the first carrying only 3 bits.
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var out [ulidLen]byte
+	for i := ulidLen - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// parseULIDTime returns the timestamp of a ULID.
+func parseULIDTime(s string) (time.Time, bool) {
+	if len(s) != ulidLen || s[0] > '7' {
+		return time.Time{}, false
+	}
+	var ms uint64
+	for i := range 10 {
+		d := strings.IndexByte(crockford, s[i])
+		if d < 0 {
+			return time.Time{}, false
+		}
+		ms = ms<<5 | uint64(d)
+	}
+	for i := 10; i < ulidLen; i++ {
+		if strings.IndexByte(crockford, s[i]) < 0 {
+			return time.Time{}, false
+		}
+	}
+	return time.UnixMilli(int64(ms)), true
+}
@@ -0,0 +1,38 @@
+package s3client
+
+import "sync"
+
+type lifecycle struct {
+	once    sync.Once
+	mu      sync.Mutex
+	closers []func()
+}
+
+// onClose registers fn to run when the client is closed. Components that
+// start background goroutines use it to stop them.
+func (c *Client) onClose(fn func()) {
+	c.life.mu.Lock()
+	defer c.life.mu.Unlock()
+	c.life.closers = append(c.life.closers, fn)
+}
+
+// Close stops background work started by the client and closes idle
+// connections of its HTTP transport. It is safe to call more than once.
+func (c *Client) Close() error {
+	c.life.once.Do(func() {
+		c.life.mu.Lock()
+		closers := c.life.closers
+		c.life.closers = nil
+		c.life.mu.Unlock()
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+		if c.cache != nil {
+			c.cache.clear()
+		}
+		if c.transport != nil {
+			c.transport.CloseIdleConnections()
+		}
+	})
+	return nil
+}
@@ -0,0 +1,38 @@
+package s3client
+
+import (
+	"io"
+	"strconv"
+)
+
+func (o callOptions) checkInMemorySize(bucket, key string, info ObjectInfo) error {
+	if o.maxInMemory <= 0 {
+		return nil
+	}
+	size := info.Size
+	if o.decompress && isCompressed(info.ContentEncoding) {
+		if n, err := strconv.ParseInt(info.Metadata[MetaUncompressedSize], 10, 64); err == nil {
+			size = n
+		}
+	}
+	if size > o.maxInMemory {
+		return &ObjectTooLargeError{Bucket: bucket, Key: key, Size: size, Limit: o.maxInMemory}
+	}
+	return nil
+}
+
+// readAll enforces the limit while reading too, since a decompressed body
+// can grow past the size announced in the response headers.
+func (o callOptions) readAll(bucket, key string, r io.Reader) ([]byte, error) {
+	if o.maxInMemory <= 0 {
+		return readPooled(r)
+	}
+	data, err := readPooled(io.LimitReader(r, o.maxInMemory+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > o.maxInMemory {
+		return nil, &ObjectTooLargeError{Bucket: bucket, Key: key, Size: int64(len(data)), Limit: o.maxInMemory}
+	}
+	return data, nil
+}
@@ -0,0 +1,188 @@
+package s3client
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func (c *Client) listPages(ctx context.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output) error) error {
+	params := *input
+	for {
+		page, err := c.listObjectsV2(ctx, &params)
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		next := aws.ToString(page.NextContinuationToken)
+		if !aws.ToBool(page.IsTruncated) || next == "" || next == aws.ToString(params.ContinuationToken) {
+			return nil
+		}
+		params.ContinuationToken = aws.String(next)
+	}
+}
+
+// listObjectsV2 issues one ListObjectsV2 request, or the equivalent v1
+// ListObjects request when Config.ListObjectsV1 is set or the server has
+// answered a v2 listing with NotImplemented. The v1 marker is returned as
+// the continuation token.
+func (c *Client) listObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	if !c.cfg.ListObjectsV1 && !c.listV1.Load() {
+		output, err := c.s3Client.ListObjectsV2(ctx, input)
+		if err == nil || !isNotImplemented(err) {
+			return output, err
+		}
+		c.listV1.Store(true)
+	}
+	marker := input.StartAfter
+	if aws.ToString(input.ContinuationToken) != "" {
+		marker = input.ContinuationToken
+	}
+	output, err := c.s3Client.ListObjects(ctx, &s3.ListObjectsInput{
+		Bucket:              input.Bucket,
+		Prefix:              input.Prefix,
+		Delimiter:           input.Delimiter,
+		MaxKeys:             input.MaxKeys,
+		Marker:              marker,
+		EncodingType:        input.EncodingType,
+		ExpectedBucketOwner: input.ExpectedBucketOwner,
+		RequestPayer:        input.RequestPayer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	v2 := &s3.ListObjectsV2Output{
+		Contents:       output.Contents,
+		CommonPrefixes: output.CommonPrefixes,
+		Delimiter:      output.Delimiter,
+		EncodingType:   output.EncodingType,
+		IsTruncated:    output.IsTruncated,
+		MaxKeys:        output.MaxKeys,
+		Name:           output.Name,
+		Prefix:         output.Prefix,
+		StartAfter:     input.StartAfter,
+		KeyCount:       aws.Int32(int32(len(output.Contents) + len(output.CommonPrefixes))),
+		ResultMetadata: output.ResultMetadata,
+	}
+	if aws.ToBool(output.IsTruncated) {
+		v2.NextContinuationToken = nextMarker(output)
+	}
+	return v2, nil
+}
+
+// nextMarker returns NextMarker, which servers only send for delimited
+// listings, or else the greatest key or prefix on the page.
+func nextMarker(output *s3.ListObjectsOutput) *string {
+	if aws.ToString(output.NextMarker) != "" {
+		return output.NextMarker
+	}
+	var last string
+	if n := len(output.Contents); n > 0 {
+		last = aws.ToString(output.Contents[n-1].Key)
+	}
+	if n := len(output.CommonPrefixes); n > 0 {
+		last = max(last, aws.ToString(output.CommonPrefixes[n-1].Prefix))
+	}
+	if last == "" {
+		return nil
+	}
+	return aws.String(last)
+}
+
+func (c *Client) listObjects(ctx context.Context, bucket, prefix string, fn func(types.Object) error) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	return c.listPages(ctx, input, func(page *s3.ListObjectsV2Output) error {
+		for _, obj := range page.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type DirListing struct {
+	Prefixes []string
+	Objects  []ObjectInfo
+}
+
+func (c *Client) ListPrefixes(ctx context.Context, bucket, prefix, delimiter string) ([]string, error) {
+	dir, err := c.ListDir(ctx, bucket, prefix, delimiter)
+	if err != nil {
+		return nil, err
+	}
+	return dir.Prefixes, nil
+}
+
+// ListDir lists one level below prefix: the common prefixes ("directories")
+// and the objects that sit directly under it. delimiter defaults to "/".
+func (c *Client) ListDir(ctx context.Context, bucket, prefix, delimiter string) (DirListing, error) {
+	if delimiter == "" {
+		delimiter = "/"
+	}
+	var dir DirListing
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String(delimiter),
+	}
+	err := c.listPages(ctx, input, func(page *s3.ListObjectsV2Output) error {
+		for _, p := range page.CommonPrefixes {
+			if p.Prefix != nil {
+				dir.Prefixes = append(dir.Prefixes, *p.Prefix)
+			}
+		}
+		for _, obj := range page.Contents {
+			if obj.Key != nil {
+				dir.Objects = append(dir.Objects, objectInfo(obj))
+			}
+		}
+		return nil
+	})
+	return dir, err
+}
+
+type ListPage struct {
+	Objects   []ObjectInfo
+	Prefixes  []string
+	NextToken string
+}
+
+// ListObjectsPage issues a single listing request. Pass NextToken back as
+// opts.ContinuationToken to fetch the following page; it is empty on the
+// last page. Filters in opts apply to the returned objects only, so a page
+// may hold fewer than MaxKeys entries.
+func (c *Client) ListObjectsPage(ctx context.Context, bucket, prefix string, opts ListOptions) (ListPage, error) {
+	output, err := c.listObjectsV2(ctx, opts.input(bucket, prefix))
+	if err != nil {
+		return ListPage{}, err
+	}
+	var page ListPage
+	for _, obj := range output.Contents {
+		if obj.Key == nil {
+			continue
+		}
+		if info := objectInfo(obj); opts.match(info) {
+			page.Objects = append(page.Objects, info)
+		}
+	}
+	for _, p := range output.CommonPrefixes {
+		if p.Prefix != nil {
+			page.Prefixes = append(page.Prefixes, *p.Prefix)
+		}
+	}
+	if aws.ToBool(output.IsTruncated) {
+		page.NextToken = aws.ToString(output.NextContinuationToken)
+	}
+	return page, nil
+}
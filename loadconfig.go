@@ -0,0 +1,339 @@
+package s3client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnv names the environment variable NewFromEnv reads a config file
+// path from.
+const ConfigEnv = "S3CLIENT_CONFIG"
+
+// fileConfig is the settings LoadConfig reads; the JSON and YAML keys are
+// the same.
+type fileConfig struct {
+	Provider        string `json:"provider" yaml:"provider"`
+	Endpoint        string `json:"endpoint" yaml:"endpoint"`
+	AccessKeyID     string `json:"access_key_id" yaml:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key" yaml:"secret_access_key"`
+	Region          string `json:"region" yaml:"region"`
+	DefaultBucket   string `json:"default_bucket" yaml:"default_bucket"`
+	AddressingStyle string `json:"addressing_style" yaml:"addressing_style"`
+	RetryMode       string `json:"retry_mode" yaml:"retry_mode"`
+	MaxAttempts     int    `json:"max_attempts" yaml:"max_attempts"`
+	Compression     string `json:"compression" yaml:"compression"`
+	Decompress      bool   `json:"decompress" yaml:"decompress"`
+
+	DefaultHeaders map[string]string `json:"default_headers" yaml:"default_headers"`
+
+	MultipartThreshold    int64 `json:"multipart_threshold" yaml:"multipart_threshold"`
+	MaxInMemoryObjectSize int64 `json:"max_in_memory_object_size" yaml:"max_in_memory_object_size"`
+	ReadRetries           int   `json:"read_retries" yaml:"read_retries"`
+	UploadBufferSize      int   `json:"upload_buffer_size" yaml:"upload_buffer_size"`
+	DownloadBufferSize    int   `json:"download_buffer_size" yaml:"download_buffer_size"`
+
+	Transport *struct {
+		MaxIdleConns        int            `json:"max_idle_conns" yaml:"max_idle_conns"`
+		MaxIdleConnsPerHost int            `json:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host"`
+		MaxConnsPerHost     int            `json:"max_conns_per_host" yaml:"max_conns_per_host"`
+		IdleConnTimeout     configDuration `json:"idle_conn_timeout" yaml:"idle_conn_timeout"`
+		DialTimeout         configDuration `json:"dial_timeout" yaml:"dial_timeout"`
+		KeepAlive           configDuration `json:"keep_alive" yaml:"keep_alive"`
+		TLSHandshakeTimeout configDuration `json:"tls_handshake_timeout" yaml:"tls_handshake_timeout"`
+		DisableHTTP2        bool           `json:"disable_http2" yaml:"disable_http2"`
+		ForceHTTP2          bool           `json:"force_http2" yaml:"force_http2"`
+	} `json:"transport" yaml:"transport"`
+
+	TLS *struct {
+		CAFile             string `json:"ca_file" yaml:"ca_file"`
+		CertFile           string `json:"cert_file" yaml:"cert_file"`
+		KeyFile            string `json:"key_file" yaml:"key_file"`
+		InsecureSkipVerify bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+	} `json:"tls" yaml:"tls"`
+}
+
+// configDuration reads durations written like "30s".
+type configDuration time.Duration
+
+func (d *configDuration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	*d = configDuration(v)
+	return err
+}
+
+// LoadConfig reads a JSON or YAML config file, chosen by its extension,
+// and validates the result. Relative TLS file paths are resolved against
+// the directory of the file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var fc fileConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&fc)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&fc)
+	default:
+		return Config{}, fmt.Errorf("s3client: config %s: unknown format, want .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("s3client: config %s: %w", path, err)
+	}
+
+	cfg := fc.config()
+	if tc := cfg.Transport; tc != nil {
+		dir := filepath.Dir(path)
+		for _, p := range []*string{&tc.CAFile, &tc.CertFile, &tc.KeyFile} {
+			if *p != "" && !filepath.IsAbs(*p) {
+				*p = filepath.Join(dir, *p)
+			}
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("s3client: config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (fc fileConfig) config() Config {
+	cfg := Config{
+		Provider:              Provider(fc.Provider),
+		Endpoint:              fc.Endpoint,
+		AccessKeyID:           fc.AccessKeyID,
+		SecretAccessKey:       fc.SecretAccessKey,
+		Region:                fc.Region,
+		DefaultBucket:         fc.DefaultBucket,
+		AddressingStyle:       AddressingStyle(fc.AddressingStyle),
+		RetryMode:             aws.RetryMode(fc.RetryMode),
+		MaxAttempts:           fc.MaxAttempts,
+		Compression:           Compression(fc.Compression),
+		Decompress:            fc.Decompress,
+		DefaultHeaders:        fc.DefaultHeaders,
+		MultipartThreshold:    fc.MultipartThreshold,
+		MaxInMemoryObjectSize: fc.MaxInMemoryObjectSize,
+		ReadRetries:           fc.ReadRetries,
+		UploadBufferSize:      fc.UploadBufferSize,
+		DownloadBufferSize:    fc.DownloadBufferSize,
+	}
+	if t := fc.Transport; t != nil {
+		cfg.Transport = &TransportConfig{
+			MaxIdleConns:        t.MaxIdleConns,
+			MaxIdleConnsPerHost: t.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     t.MaxConnsPerHost,
+			IdleConnTimeout:     time.Duration(t.IdleConnTimeout),
+			DialTimeout:         time.Duration(t.DialTimeout),
+			KeepAlive:           time.Duration(t.KeepAlive),
+			TLSHandshakeTimeout: time.Duration(t.TLSHandshakeTimeout),
+			DisableHTTP2:        t.DisableHTTP2,
+			ForceHTTP2:          t.ForceHTTP2,
+		}
+	}
+	if t := fc.TLS; t != nil {
+		if cfg.Transport == nil {
+			cfg.Transport = &TransportConfig{}
+		}
+		cfg.Transport.CAFile = t.CAFile
+		cfg.Transport.CertFile = t.CertFile
+		cfg.Transport.KeyFile = t.KeyFile
+		cfg.Transport.InsecureSkipVerify = t.InsecureSkipVerify
+	}
+	return cfg
+}
+
+// ConfigFromEnv builds a Config from the file named by S3CLIENT_CONFIG, if
+// set, overridden by these environment variables:
+//
+//	S3CLIENT_PROVIDER, S3CLIENT_ENDPOINT, S3CLIENT_ACCESS_KEY_ID,
+//	S3CLIENT_SECRET_ACCESS_KEY, S3CLIENT_REGION, S3CLIENT_DEFAULT_BUCKET,
+//	S3CLIENT_ADDRESSING_STYLE, S3CLIENT_RETRY_MODE, S3CLIENT_MAX_ATTEMPTS,
+//	S3CLIENT_COMPRESSION, S3CLIENT_MULTIPART_THRESHOLD,
+//	S3CLIENT_CA_FILE, S3CLIENT_CERT_FILE, S3CLIENT_KEY_FILE and
+//	S3CLIENT_INSECURE_SKIP_VERIFY.
+//
+// The AWS variables other tools share only fill in what is still unset:
+// AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL the endpoint, AWS_REGION or
+// AWS_DEFAULT_REGION the region, and AWS_ACCESS_KEY_ID with
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN the credentials.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	if path := os.Getenv(ConfigEnv); path != "" {
+		var err error
+		if cfg, err = LoadConfig(path); err != nil {
+			return Config{}, err
+		}
+	}
+	var errs []error
+	str := func(dst *string, names ...string) {
+		for _, name := range names {
+			if v := os.Getenv(name); v != "" {
+				*dst = v
+				return
+			}
+		}
+	}
+	fallback := func(dst *string, names ...string) {
+		if *dst == "" {
+			str(dst, names...)
+		}
+	}
+	num := func(name string, set func(int64)) {
+		if v := os.Getenv(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			set(n)
+		}
+	}
+	transport := func() *TransportConfig {
+		if cfg.Transport == nil {
+			cfg.Transport = &TransportConfig{}
+		}
+		return cfg.Transport
+	}
+
+	str((*string)(&cfg.Provider), "S3CLIENT_PROVIDER")
+	str(&cfg.Endpoint, "S3CLIENT_ENDPOINT")
+	fallback(&cfg.Endpoint, "AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL")
+	str(&cfg.AccessKeyID, "S3CLIENT_ACCESS_KEY_ID")
+	str(&cfg.SecretAccessKey, "S3CLIENT_SECRET_ACCESS_KEY")
+	// The AWS key pair, and the session token that goes with it, is taken
+	// as a whole so it never mixes with a configured key.
+	if cfg.AccessKeyID == "" && cfg.SecretAccessKey == "" {
+		cfg.AccessKeyID, cfg.SecretAccessKey = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" && cfg.AccessKeyID != "" {
+			cfg.Credentials = credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, token)
+		}
+	}
+	str(&cfg.Region, "S3CLIENT_REGION")
+	fallback(&cfg.Region, "AWS_REGION", "AWS_DEFAULT_REGION")
+	str(&cfg.DefaultBucket, "S3CLIENT_DEFAULT_BUCKET")
+	str((*string)(&cfg.AddressingStyle), "S3CLIENT_ADDRESSING_STYLE")
+	str((*string)(&cfg.RetryMode), "S3CLIENT_RETRY_MODE")
+	str((*string)(&cfg.Compression), "S3CLIENT_COMPRESSION")
+	num("S3CLIENT_MAX_ATTEMPTS", func(n int64) { cfg.MaxAttempts = int(n) })
+	num("S3CLIENT_MULTIPART_THRESHOLD", func(n int64) { cfg.MultipartThreshold = n })
+	for name, dst := range map[string]func() *string{
+		"S3CLIENT_CA_FILE":   func() *string { return &transport().CAFile },
+		"S3CLIENT_CERT_FILE": func() *string { return &transport().CertFile },
+		"S3CLIENT_KEY_FILE":  func() *string { return &transport().KeyFile },
+	} {
+		if v := os.Getenv(name); v != "" {
+			*dst() = v
+		}
+	}
+	if v := os.Getenv("S3CLIENT_INSECURE_SKIP_VERIFY"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("S3CLIENT_INSECURE_SKIP_VERIFY: %w", err))
+		}
+		transport().InsecureSkipVerify = b
+	}
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("s3client: environment: %w", errors.Join(errs...))
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// NewFromEnv creates a client from ConfigFromEnv.
+func NewFromEnv() (*Client, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg)
+}
+
+// Validate reports every setting New would reject or that is likely a
+// mistake, joined into one error.
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	if _, ok := providerProfiles[c.Provider]; !ok {
+		fail("provider %q is not one of aws, minio, r2, b2, ceph, wasabi", c.Provider)
+	}
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		switch {
+		case err != nil:
+			fail("endpoint: %v", err)
+		case u.Scheme != "http" && u.Scheme != "https":
+			fail("endpoint %q needs an http:// or https:// scheme", c.Endpoint)
+		case u.Host == "":
+			fail("endpoint %q has no host", c.Endpoint)
+		}
+	} else if c.Provider != ProviderAWS && c.Provider != ProviderGeneric && c.EndpointResolver == nil {
+		fail("provider %s needs an endpoint", c.Provider)
+	}
+	if c.Credentials == nil && (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+		fail("access key ID and secret access key must be set together")
+	}
+	switch c.AddressingStyle {
+	case AddressingAuto, AddressingPath, AddressingVirtual:
+	default:
+		fail("addressing style %q is not path or virtual", c.AddressingStyle)
+	}
+	switch c.RetryMode {
+	case "", aws.RetryModeStandard, aws.RetryModeAdaptive:
+	default:
+		fail("retry mode %q is not standard or adaptive", c.RetryMode)
+	}
+	switch c.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		fail("compression %q is not gzip or zstd", c.Compression)
+	}
+	if c.MaxAttempts < 0 {
+		fail("max attempts must not be negative")
+	}
+	if c.MultipartThreshold != 0 && c.MultipartThreshold < minPartSize {
+		fail("multipart threshold %d is below the 5 MiB minimum part size", c.MultipartThreshold)
+	}
+	if c.MaxInMemoryObjectSize < 0 || c.ReadRetries < 0 || c.UploadBufferSize < 0 || c.DownloadBufferSize < 0 {
+		fail("sizes and retry counts must not be negative")
+	}
+	if tc := c.Transport; tc != nil {
+		if (tc.CertFile == "") != (tc.KeyFile == "") {
+			fail("TLS cert file and key file must be set together")
+		}
+		for _, p := range []string{tc.CAFile, tc.CertFile, tc.KeyFile} {
+			if p == "" {
+				continue
+			}
+			if _, err := os.Stat(p); err != nil {
+				fail("TLS: %v", err)
+			}
+		}
+		if tc.DisableHTTP2 && tc.ForceHTTP2 {
+			fail("transport cannot both disable and force HTTP/2")
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("s3client: invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
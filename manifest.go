@@ -0,0 +1,206 @@
+package s3client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ManifestName is the object GenerateChecksumManifest writes below the
+// prefix, in the format of sha256sum.
+const ManifestName = "SHA256SUMS"
+
+const manifestConcurrency = 8
+
+type ManifestReport struct {
+	Verified   int
+	Mismatched []string
+	Missing    []string
+}
+
+// OK reports whether every manifest entry was found with its checksum.
+func (r ManifestReport) OK() bool {
+	return len(r.Mismatched) == 0 && len(r.Missing) == 0
+}
+
+type VerifyOptions struct {
+	// UseStoredChecksums compares against checksums kept with the object
+	// (MetaContentSHA256 or a full-object SHA-256 checksum) instead of
+	// downloading it, falling back to a download when there is none.
+	UseStoredChecksums bool
+	Concurrency        int
+}
+
+// GenerateChecksumManifest hashes every object under prefix and stores
+// the result as prefix+ManifestName, returning the manifest key. Objects
+// are hashed as stored, without decompression.
+func (c *Client) GenerateChecksumManifest(ctx context.Context, bucket, prefix string) (string, error) {
+	manifestKey := prefix + ManifestName
+	var keys []string
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		if key := aws.ToString(obj.Key); key != manifestKey {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sums := make([]string, len(keys))
+	err = forEachConcurrent(len(keys), manifestConcurrency, func(i int) error {
+		sum, err := c.hashObject(ctx, bucket, keys[i])
+		sums[i] = sum
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	for i, key := range keys {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[i], strings.TrimPrefix(key, prefix))
+	}
+	if err := c.PutObjectBytes(ctx, bucket, manifestKey, buf.Bytes(), "text/plain", WithCompression(CompressionNone)); err != nil {
+		return "", err
+	}
+	return manifestKey, nil
+}
+
+// VerifyManifest checks the objects listed in prefix+ManifestName. Missing
+// and mismatching objects are reported rather than returned as errors.
+func (c *Client) VerifyManifest(ctx context.Context, bucket, prefix string, opts VerifyOptions) (ManifestReport, error) {
+	data, err := c.GetObjectBytes(ctx, bucket, prefix+ManifestName, WithMaxInMemorySize(0))
+	if err != nil {
+		return ManifestReport{}, err
+	}
+	type entry struct{ sum, key string }
+	var entries []entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		sum, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return ManifestReport{}, fmt.Errorf("s3client: malformed manifest line %q", scanner.Text())
+		}
+		entries = append(entries, entry{sum, prefix + name})
+	}
+	if err := scanner.Err(); err != nil {
+		return ManifestReport{}, err
+	}
+
+	var (
+		mu     sync.Mutex
+		report ManifestReport
+	)
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = manifestConcurrency
+	}
+	err = forEachConcurrent(len(entries), concurrency, func(i int) error {
+		e := entries[i]
+		var sum string
+		var err error
+		if opts.UseStoredChecksums {
+			sum, err = c.storedSHA256(ctx, bucket, e.key)
+		}
+		if err == nil && sum == "" {
+			sum, err = c.hashObject(ctx, bucket, e.key)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case isNotFound(err):
+			report.Missing = append(report.Missing, e.key)
+		case err != nil:
+			return err
+		case sum != e.sum:
+			report.Mismatched = append(report.Mismatched, e.key)
+		default:
+			report.Verified++
+		}
+		return nil
+	})
+	sort.Strings(report.Missing)
+	sort.Strings(report.Mismatched)
+	return report, err
+}
+
+func (c *Client) hashObject(ctx context.Context, bucket, key string) (string, error) {
+	body, err := c.GetObject(ctx, bucket, key, WithDecompression(false))
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// storedSHA256 returns the hex SHA-256 of the stored bytes when the object
+// carries one, or "" when it must be downloaded.
+func (c *Client) storedSHA256(ctx context.Context, bucket, key string) (string, error) {
+	head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return "", err
+	}
+	if sum, ok := head.Metadata[MetaContentSHA256]; ok && !isCompressed(aws.ToString(head.ContentEncoding)) {
+		return sum, nil
+	}
+	if head.ChecksumSHA256 != nil && head.ChecksumType == types.ChecksumTypeFullObject {
+		raw, err := base64.StdEncoding.DecodeString(*head.ChecksumSHA256)
+		if err == nil {
+			return hex.EncodeToString(raw), nil
+		}
+	}
+	return "", nil
+}
+
+// forEachConcurrent calls fn for 0..n-1 with up to concurrency calls in
+// flight and returns the first error, after which no new calls start.
+func forEachConcurrent(n, concurrency int, fn func(i int) error) error {
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+	)
+	for i := 0; i < n; i++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			if err := fn(i); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,141 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func inputField(params any, name string) string {
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() || f.Kind() != reflect.Pointer || f.IsNil() || f.Elem().Kind() != reflect.String {
+		return ""
+	}
+	return f.Elem().String()
+}
+
+func addOperationError(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("s3client.OperationError",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, md, err := next.HandleInitialize(ctx, in)
+			if err != nil {
+				err = newOperationError(middleware.GetOperationName(ctx), in.Parameters, err)
+			}
+			return out, md, err
+		}), middleware.Before)
+}
+
+// setInputField sets the string or *string field name of params to value
+// unless it is already set or params has no such field.
+func setInputField(params any, name, value string) {
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	f := v.Elem().FieldByName(name)
+	if !f.IsValid() || !f.CanSet() {
+		return
+	}
+	switch {
+	case f.Kind() == reflect.String && f.String() == "":
+		f.SetString(value)
+	case f.Kind() == reflect.Pointer && f.IsNil() && f.Type().Elem().Kind() == reflect.String:
+		p := reflect.New(f.Type().Elem())
+		p.Elem().SetString(value)
+		f.Set(p)
+	}
+}
+
+type attemptLimitKey struct{}
+
+type attemptLimit struct {
+	max, n int
+}
+
+type noRetryError struct{ error }
+
+func (e noRetryError) Unwrap() error        { return e.error }
+func (e noRetryError) RetryableError() bool { return false }
+
+// addCallOptions applies the request-level options attached to the context
+// by WithCallOptions or a method's CallOptions.
+func addCallOptions(stack *middleware.Stack) error {
+	err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("s3client.CallOptions",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			opts := contextCallOptions(ctx)
+			if len(opts) == 0 {
+				return next.HandleInitialize(ctx, in)
+			}
+			var o callOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			if o.encryption != "" {
+				setInputField(in.Parameters, "ServerSideEncryption", string(o.encryption))
+				if o.kmsKeyID != "" {
+					setInputField(in.Parameters, "SSEKMSKeyId", o.kmsKeyID)
+				}
+			}
+			if o.storageClass != "" {
+				setInputField(in.Parameters, "StorageClass", string(o.storageClass))
+			}
+			if o.requesterPays {
+				setInputField(in.Parameters, "RequestPayer", string(types.RequestPayerRequester))
+			}
+			if o.maxAttempts > 0 {
+				ctx = context.WithValue(ctx, attemptLimitKey{}, &attemptLimit{max: o.maxAttempts})
+			}
+			if o.timeout <= 0 {
+				return next.HandleInitialize(ctx, in)
+			}
+			ctx, cancel := context.WithTimeout(ctx, o.timeout)
+			out, md, err := next.HandleInitialize(ctx, in)
+			// The timeout also covers reading a GetObject body.
+			if output, ok := out.Result.(*s3.GetObjectOutput); ok && err == nil && output.Body != nil {
+				output.Body = &cancelOnClose{ReadCloser: output.Body, cancel: cancel}
+			} else {
+				cancel()
+			}
+			return out, md, err
+		}), middleware.Before)
+	if err != nil {
+		return err
+	}
+	if _, ok := stack.Finalize.Get("Retry"); !ok {
+		return nil
+	}
+	return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("s3client.MaxAttempts",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			out, md, err := next.HandleFinalize(ctx, in)
+			if limit, ok := ctx.Value(attemptLimitKey{}).(*attemptLimit); ok && err != nil {
+				if limit.n++; limit.n >= limit.max {
+					err = noRetryError{err}
+				}
+			}
+			return out, md, err
+		}), "Retry", middleware.After)
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,134 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mkchar/s3client/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Object is a handle for a single key in a bucket.
+type Object struct {
+	b   *Bucket
+	key string
+}
+
+func (b *Bucket) Object(key string) *Object {
+	return &Object{b: b, key: key}
+}
+
+func (o *Object) Key() string {
+	return o.key
+}
+
+func (o *Object) Bucket() *Bucket {
+	return o.b
+}
+
+func (o *Object) Reader(ctx context.Context, opts ...CallOption) (io.ReadCloser, error) {
+	return o.b.Get(ctx, o.key, opts...)
+}
+
+func (o *Object) Stat(ctx context.Context) (ObjectInfo, error) {
+	return o.b.Stat(ctx, o.key)
+}
+
+func (o *Object) Exists(ctx context.Context) (bool, error) {
+	return o.b.ObjectExists(ctx, o.key)
+}
+
+func (o *Object) Delete(ctx context.Context) error {
+	return o.b.Delete(ctx, o.key)
+}
+
+func (o *Object) CopyTo(ctx context.Context, dst *Object) error {
+	return o.b.c.CopyObject(ctx, o.b.name, o.key, dst.b.name, dst.key)
+}
+
+func (o *Object) Presign(ctx context.Context, expiry time.Duration, opts ...CallOption) (string, error) {
+	return o.b.PresignGet(ctx, o.key, expiry, opts...)
+}
+
+func (o *Object) PresignPut(ctx context.Context, expiry time.Duration) (string, error) {
+	return o.b.PresignPut(ctx, o.key, expiry)
+}
+
+// Writer returns a writer that streams to the object through the multipart
+// uploader. The object is only created once Close returns nil. Set
+// ContentType before the first Write; it defaults to a type derived from
+// the key's extension.
+func (o *Object) Writer(ctx context.Context, opts ...CallOption) *ObjectWriter {
+	return &ObjectWriter{ctx: ctx, o: o, opts: opts}
+}
+
+type ObjectWriter struct {
+	ContentType string
+	Metadata    map[string]string
+
+	ctx  context.Context
+	o    *Object
+	opts []CallOption
+
+	once      sync.Once
+	pw        *io.PipeWriter
+	done      chan error
+	closeOnce sync.Once
+	err       error
+}
+
+func (w *ObjectWriter) start() {
+	w.once.Do(func() {
+		contentType := w.ContentType
+		if contentType == "" {
+			contentType = utils.DetectContentType(w.o.key)
+		}
+		pr, pw := io.Pipe()
+		w.pw = pw
+		w.done = make(chan error, 1)
+		c := w.o.b.c
+		ctx, o := c.callOptions(w.ctx, w.opts)
+		input := &s3.PutObjectInput{
+			Bucket:      aws.String(w.o.b.name),
+			Key:         aws.String(w.o.key),
+			Body:        pr,
+			ContentType: aws.String(contentType),
+			Metadata:    w.Metadata,
+		}
+		c.invalidate(w.o.b.name, w.o.key)
+		go func() {
+			var err error
+			if o.compression != CompressionNone {
+				err = c.putCompressed(ctx, input, -1, o)
+			} else {
+				_, err = c.upload(ctx, input, o.uploaderOptions)
+			}
+			pr.CloseWithError(err)
+			w.done <- err
+		}()
+	})
+}
+
+func (w *ObjectWriter) Write(p []byte) (int, error) {
+	w.start()
+	return w.pw.Write(p)
+}
+
+func (w *ObjectWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError abandons the upload when err is non-nil; no object is
+// created in that case.
+func (w *ObjectWriter) CloseWithError(err error) error {
+	w.start()
+	w.closeOnce.Do(func() {
+		w.pw.CloseWithError(err)
+		w.err = <-w.done
+	})
+	return w.err
+}
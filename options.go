@@ -0,0 +1,199 @@
+package s3client
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	compression Compression
+	decompress  bool
+	maxInMemory int64
+	readRetries int
+	concurrency int
+	partSize    int64
+	versionID   string
+
+	multipartThreshold int64
+	memoryLimit        int64
+
+	skipUnchanged bool
+
+	// Applied to every request by the call-options middleware.
+	timeout       time.Duration
+	maxAttempts   int
+	requesterPays bool
+	encryption    types.ServerSideEncryption
+	kmsKeyID      string
+	storageClass  types.StorageClass
+}
+
+type callOptionsKey struct{}
+
+// WithCallOptions returns a context that applies opts to every call made
+// with it, including methods that take no options of their own. Options
+// passed to a method are applied after these.
+func WithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	return context.WithValue(ctx, callOptionsKey{}, append(contextCallOptions(ctx), opts...))
+}
+
+func contextCallOptions(ctx context.Context) []CallOption {
+	opts, _ := ctx.Value(callOptionsKey{}).([]CallOption)
+	return opts[:len(opts):len(opts)]
+}
+
+// callOptions resolves opts over the context options and client defaults.
+// The returned context carries opts on to the requests of the call.
+func (c *Client) callOptions(ctx context.Context, opts []CallOption) (context.Context, callOptions) {
+	o := callOptions{
+		compression: c.cfg.Compression,
+		decompress:  c.cfg.Decompress,
+		maxInMemory: c.cfg.MaxInMemoryObjectSize,
+		readRetries: c.cfg.ReadRetries,
+
+		multipartThreshold: c.cfg.MultipartThreshold,
+	}
+	for _, opt := range contextCallOptions(ctx) {
+		opt(&o)
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(opts) > 0 {
+		ctx = WithCallOptions(ctx, opts...)
+	}
+	return ctx, o
+}
+
+func WithCompression(alg Compression) CallOption {
+	return func(o *callOptions) {
+		o.compression = alg
+	}
+}
+
+func WithDecompression(enabled bool) CallOption {
+	return func(o *callOptions) {
+		o.decompress = enabled
+	}
+}
+
+// WithMaxInMemorySize overrides Config.MaxInMemoryObjectSize for one call;
+// zero disables the limit.
+func WithMaxInMemorySize(n int64) CallOption {
+	return func(o *callOptions) {
+		o.maxInMemory = n
+	}
+}
+
+// WithReadRetries lets GetObject readers transparently resume up to n times
+// after a mid-stream read failure.
+func WithReadRetries(n int) CallOption {
+	return func(o *callOptions) {
+		o.readRetries = n
+	}
+}
+
+// WithConcurrency sets how many parts transfers move in parallel.
+func WithConcurrency(n int) CallOption {
+	return func(o *callOptions) {
+		o.concurrency = n
+	}
+}
+
+func WithPartSize(n int64) CallOption {
+	return func(o *callOptions) {
+		o.partSize = n
+	}
+}
+
+// WithMemoryLimit bounds the part buffers a transfer keeps in flight by
+// lowering its concurrency to limit/part size, at least one part.
+func WithMemoryLimit(limit int64) CallOption {
+	return func(o *callOptions) {
+		o.memoryLimit = limit
+	}
+}
+
+// WithMultipartThreshold sets the size from which Upload switches to a
+// multipart upload.
+func WithMultipartThreshold(n int64) CallOption {
+	return func(o *callOptions) {
+		o.multipartThreshold = n
+	}
+}
+
+// WithVersionID addresses a specific object version in presigned GET URLs.
+func WithVersionID(id string) CallOption {
+	return func(o *callOptions) {
+		o.versionID = id
+	}
+}
+
+// WithTimeout bounds each S3 request of a call, including its retries and,
+// for GetObject, reading the body.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// WithMaxAttempts limits the attempts of each request. It can only lower
+// Config.MaxAttempts; one disables retries.
+func WithMaxAttempts(n int) CallOption {
+	return func(o *callOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// WithRequesterPays accepts the charges for requests to Requester Pays
+// buckets.
+func WithRequesterPays() CallOption {
+	return func(o *callOptions) {
+		o.requesterPays = true
+	}
+}
+
+// WithEncryption requests server-side encryption for created objects.
+// kmsKeyID selects the key for types.ServerSideEncryptionAwsKms and may be
+// empty for the account default.
+func WithEncryption(sse types.ServerSideEncryption, kmsKeyID string) CallOption {
+	return func(o *callOptions) {
+		o.encryption = sse
+		o.kmsKeyID = kmsKeyID
+	}
+}
+
+func WithStorageClass(class types.StorageClass) CallOption {
+	return func(o *callOptions) {
+		o.storageClass = class
+	}
+}
+
+func (o callOptions) uploaderOptions(u *manager.Uploader) {
+	if o.concurrency > 0 {
+		u.Concurrency = o.concurrency
+	}
+	if o.partSize > 0 {
+		u.PartSize = o.partSize
+	}
+	if o.memoryLimit > 0 && u.PartSize > 0 {
+		u.Concurrency = max(1, min(u.Concurrency, int(o.memoryLimit/u.PartSize)))
+	}
+}
+
+func (o callOptions) downloaderOptions(d *manager.Downloader) {
+	if o.concurrency > 0 {
+		d.Concurrency = o.concurrency
+	}
+	if o.partSize > 0 {
+		d.PartSize = o.partSize
+	}
+	if o.memoryLimit > 0 && d.PartSize > 0 {
+		d.Concurrency = int(max(1, min(int64(d.Concurrency), o.memoryLimit/d.PartSize)))
+	}
+}
@@ -0,0 +1,81 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxPooledBuffer keeps unusually large buffers from pinning memory in the
+// pool.
+const maxPooledBuffer = 8 << 20
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// readPooled reads r through a pooled scratch buffer so the only allocation
+// left is the exactly-sized result.
+func readPooled(r io.Reader) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// GetObjectInto appends the object body to buf, letting callers reuse
+// their own buffers across calls.
+func (c *Client) GetObjectInto(ctx context.Context, bucket, key string, buf *bytes.Buffer, opts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, opts)
+	output, err := c.getObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, o)
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+	info := getObjectInfo(key, output)
+	if err := o.checkInMemorySize(bucket, key, info); err != nil {
+		return err
+	}
+	body, err := c.objectBody(output, o)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if info.Size > 0 && !isCompressed(info.ContentEncoding) {
+		buf.Grow(int(info.Size))
+	}
+	var r io.Reader = body
+	if o.maxInMemory > 0 {
+		r = io.LimitReader(body, o.maxInMemory+1)
+	}
+	n, err := buf.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+	if o.maxInMemory > 0 && n > o.maxInMemory {
+		return &ObjectTooLargeError{Bucket: bucket, Key: key, Size: n, Limit: o.maxInMemory}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// PrefixedClient scopes object operations to keys under a fixed prefix.
+// Keys passed in are relative to the prefix and keys returned from
+// listings have it stripped, so callers cannot reach outside their scope
+// by forgetting to prepend it.
+type PrefixedClient struct {
+	c      *Client
+	prefix string
+}
+
+func (c *Client) WithPrefix(prefix string) *PrefixedClient {
+	return &PrefixedClient{c: c, prefix: prefix}
+}
+
+func (p *PrefixedClient) WithPrefix(prefix string) *PrefixedClient {
+	return &PrefixedClient{c: p.c, prefix: p.prefix + prefix}
+}
+
+func (p *PrefixedClient) Prefix() string {
+	return p.prefix
+}
+
+func (p *PrefixedClient) key(key string) string {
+	return p.prefix + strings.TrimPrefix(key, "/")
+}
+
+func (p *PrefixedClient) strip(key string) string {
+	return strings.TrimPrefix(key, p.prefix)
+}
+
+func (p *PrefixedClient) stripInfo(info ObjectInfo) ObjectInfo {
+	info.Key = p.strip(info.Key)
+	return info
+}
+
+func (p *PrefixedClient) PutObject(ctx context.Context, bucket, key string, body io.Reader, contentType string, opts ...CallOption) error {
+	return p.c.PutObject(ctx, bucket, p.key(key), body, contentType, opts...)
+}
+
+func (p *PrefixedClient) PutObjectBytes(ctx context.Context, bucket, key string, data []byte, contentType string, opts ...CallOption) error {
+	return p.c.PutObjectBytes(ctx, bucket, p.key(key), data, contentType, opts...)
+}
+
+func (p *PrefixedClient) GetObject(ctx context.Context, bucket, key string, opts ...CallOption) (io.ReadCloser, error) {
+	return p.c.GetObject(ctx, bucket, p.key(key), opts...)
+}
+
+func (p *PrefixedClient) GetObjectBytes(ctx context.Context, bucket, key string, opts ...CallOption) ([]byte, error) {
+	return p.c.GetObjectBytes(ctx, bucket, p.key(key), opts...)
+}
+
+func (p *PrefixedClient) StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	info, err := p.c.StatObject(ctx, bucket, p.key(key))
+	return p.stripInfo(info), err
+}
+
+func (p *PrefixedClient) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	return p.c.ObjectExists(ctx, bucket, p.key(key))
+}
+
+func (p *PrefixedClient) DeleteObject(ctx context.Context, bucket, key string) error {
+	return p.c.DeleteObject(ctx, bucket, p.key(key))
+}
+
+func (p *PrefixedClient) DeleteObjects(ctx context.Context, bucket string, keys []string) error {
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = p.key(key)
+	}
+	return p.c.DeleteObjects(ctx, bucket, full)
+}
+
+func (p *PrefixedClient) DeletePrefix(ctx context.Context, bucket, prefix string, opts ListOptions) (int64, error) {
+	return p.c.DeletePrefix(ctx, bucket, p.key(prefix), p.listOptions(opts))
+}
+
+func (p *PrefixedClient) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	keys, err := p.c.ListObjects(ctx, bucket, p.key(prefix))
+	for i, key := range keys {
+		keys[i] = p.strip(key)
+	}
+	return keys, err
+}
+
+func (p *PrefixedClient) ListObjectsDetailed(ctx context.Context, bucket, prefix string, opts ListOptions) ([]ObjectInfo, error) {
+	objects, err := p.c.ListObjectsDetailed(ctx, bucket, p.key(prefix), p.listOptions(opts))
+	for i := range objects {
+		objects[i] = p.stripInfo(objects[i])
+	}
+	return objects, err
+}
+
+func (p *PrefixedClient) ListDir(ctx context.Context, bucket, prefix, delimiter string) (DirListing, error) {
+	dir, err := p.c.ListDir(ctx, bucket, p.key(prefix), delimiter)
+	for i := range dir.Prefixes {
+		dir.Prefixes[i] = p.strip(dir.Prefixes[i])
+	}
+	for i := range dir.Objects {
+		dir.Objects[i] = p.stripInfo(dir.Objects[i])
+	}
+	return dir, err
+}
+
+func (p *PrefixedClient) Walk(ctx context.Context, bucket, prefix string, fn WalkFunc) error {
+	return p.c.Walk(ctx, bucket, p.key(prefix), func(info ObjectInfo) error {
+		return fn(p.stripInfo(info))
+	})
+}
+
+func (p *PrefixedClient) WalkDetailed(ctx context.Context, bucket, prefix string, prefetch int, fn WalkFunc) error {
+	return p.c.WalkDetailed(ctx, bucket, p.key(prefix), prefetch, func(info ObjectInfo) error {
+		return fn(p.stripInfo(info))
+	})
+}
+
+func (p *PrefixedClient) UploadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error {
+	return p.c.UploadFile(ctx, bucket, p.key(key), localPath, opts...)
+}
+
+func (p *PrefixedClient) DownloadFile(ctx context.Context, bucket, key, localPath string, opts ...CallOption) error {
+	return p.c.DownloadFile(ctx, bucket, p.key(key), localPath, opts...)
+}
+
+// CopyObject copies between two keys inside the prefix, possibly across
+// buckets.
+func (p *PrefixedClient) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	return p.c.CopyObject(ctx, srcBucket, p.key(srcKey), dstBucket, p.key(dstKey))
+}
+
+func (p *PrefixedClient) MoveObject(ctx context.Context, bucket, srcKey, dstKey string) error {
+	return p.c.MoveObject(ctx, bucket, p.key(srcKey), p.key(dstKey))
+}
+
+func (p *PrefixedClient) PresignGetObject(ctx context.Context, bucket, key string, expiry time.Duration, opts ...CallOption) (string, error) {
+	return p.c.PresignGetObject(ctx, bucket, p.key(key), expiry, opts...)
+}
+
+func (p *PrefixedClient) PresignPutObject(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
+	return p.c.PresignPutObject(ctx, bucket, p.key(key), expiry)
+}
+
+// listOptions keeps StartAfter, which is a full key, inside the prefix.
+func (p *PrefixedClient) listOptions(opts ListOptions) ListOptions {
+	if opts.StartAfter != "" {
+		opts.StartAfter = p.key(opts.StartAfter)
+	}
+	return opts
+}
@@ -0,0 +1,79 @@
+package s3client
+
+import (
+	"sync"
+	"time"
+)
+
+// PresignCacheConfig makes PresignGetObject return a previously generated
+// URL while it stays valid for longer than MinRemaining, which defaults to
+// half the requested expiry.
+type PresignCacheConfig struct {
+	MinRemaining time.Duration
+	// MaxEntries defaults to 10000.
+	MaxEntries int
+}
+
+type presignCache struct {
+	cfg   PresignCacheConfig
+	mu    sync.Mutex
+	items map[presignCacheKey]presignEntry
+}
+
+type presignCacheKey struct {
+	bucket, key, versionID string
+	expiry                 time.Duration
+}
+
+type presignEntry struct {
+	url     string
+	expires time.Time
+}
+
+func newPresignCache(cfg PresignCacheConfig) *presignCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	return &presignCache{cfg: cfg, items: map[presignCacheKey]presignEntry{}}
+}
+
+func (pc *presignCache) get(k presignCacheKey, now time.Time) (string, bool) {
+	minRemaining := pc.cfg.MinRemaining
+	if minRemaining <= 0 {
+		minRemaining = k.expiry / 2
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	e, ok := pc.items[k]
+	if !ok || e.expires.Sub(now) <= minRemaining {
+		return "", false
+	}
+	return e.url, true
+}
+
+func (pc *presignCache) put(k presignCacheKey, url string, expires, now time.Time) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if len(pc.items) >= pc.cfg.MaxEntries {
+		for id, e := range pc.items {
+			if !e.expires.After(now) {
+				delete(pc.items, id)
+			}
+		}
+		// Still full of live URLs: drop arbitrary entries rather than
+		// tracking recency for what is a cheap value to regenerate.
+		for id := range pc.items {
+			if len(pc.items) < pc.cfg.MaxEntries {
+				break
+			}
+			delete(pc.items, id)
+		}
+	}
+	pc.items[k] = presignEntry{url: url, expires: expires}
+}
+
+func (pc *presignCache) clear() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	clear(pc.items)
+}
@@ -0,0 +1,223 @@
+package s3client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var (
+	ErrInvalidPresignedURL = errors.New("s3client: invalid presigned URL")
+	ErrPresignedURLExpired = errors.New("s3client: presigned URL expired")
+)
+
+// PresignedURLInfo describes what a validated presigned URL grants.
+type PresignedURLInfo struct {
+	Method        string
+	Bucket        string
+	Key           string
+	VersionID     string
+	AccessKeyID   string
+	Region        string
+	Service       string
+	SignedAt      time.Time
+	Expires       time.Time
+	SignedHeaders []string
+}
+
+const presignClockSkew = 5 * time.Minute
+
+// ValidatePresignedURL checks the query-string SigV4 signature, expiry and
+// access key of rawURL against creds. header supplies the values of signed
+// headers other than host. Bucket and Key are taken from the URL path for
+// path-style URLs and from the first host label otherwise; use the
+// Client method when the endpoint is a custom one.
+func ValidatePresignedURL(method, rawURL string, header http.Header, creds aws.Credentials, now time.Time) (PresignedURLInfo, error) {
+	return validatePresignedURL(method, rawURL, header, creds, now, "")
+}
+
+// ValidatePresignedURL validates rawURL against the client's current
+// credentials and endpoint.
+func (c *Client) ValidatePresignedURL(ctx context.Context, method, rawURL string, header http.Header) (PresignedURLInfo, error) {
+	creds, err := c.creds.cache.Retrieve(ctx)
+	if err != nil {
+		return PresignedURLInfo{}, err
+	}
+	var endpointHost string
+	if u, err := url.Parse(c.cfg.Endpoint); err == nil {
+		endpointHost = u.Hostname()
+	}
+	return validatePresignedURL(method, rawURL, header, creds, time.Now(), endpointHost)
+}
+
+func validatePresignedURL(method, rawURL string, header http.Header, creds aws.Credentials, now time.Time, endpointHost string) (PresignedURLInfo, error) {
+	invalid := func(format string, args ...any) (PresignedURLInfo, error) {
+		return PresignedURLInfo{}, fmt.Errorf("%w: %s", ErrInvalidPresignedURL, fmt.Sprintf(format, args...))
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	query := u.Query()
+	if alg := query.Get("X-Amz-Algorithm"); alg != "AWS4-HMAC-SHA256" {
+		return invalid("unsupported algorithm %q", alg)
+	}
+	scope := strings.Split(query.Get("X-Amz-Credential"), "/")
+	if len(scope) != 5 || scope[4] != "aws4_request" {
+		return invalid("malformed credential scope")
+	}
+	signedAt, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+	if err != nil {
+		return invalid("malformed X-Amz-Date")
+	}
+	if scope[1] != signedAt.Format("20060102") {
+		return invalid("credential scope date does not match X-Amz-Date")
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires <= 0 || expires > 7*24*3600 {
+		return invalid("malformed X-Amz-Expires")
+	}
+	signature := query.Get("X-Amz-Signature")
+	if signature == "" {
+		return invalid("missing signature")
+	}
+
+	info := PresignedURLInfo{
+		Method:        strings.ToUpper(method),
+		VersionID:     query.Get("versionId"),
+		AccessKeyID:   scope[0],
+		Region:        scope[2],
+		Service:       scope[3],
+		SignedAt:      signedAt,
+		Expires:       signedAt.Add(time.Duration(expires) * time.Second),
+		SignedHeaders: strings.Split(query.Get("X-Amz-SignedHeaders"), ";"),
+	}
+	info.Bucket, info.Key = presignedBucketKey(u, endpointHost)
+
+	if info.AccessKeyID != creds.AccessKeyID {
+		return invalid("signed with access key %s", info.AccessKeyID)
+	}
+	if tok := query.Get("X-Amz-Security-Token"); tok != creds.SessionToken {
+		return invalid("session token does not match")
+	}
+
+	query.Del("X-Amz-Signature")
+	var headers strings.Builder
+	for _, name := range info.SignedHeaders {
+		value := header.Get(name)
+		if name == "host" {
+			value = sanitizeHost(u)
+		}
+		headers.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+	payload := query.Get("X-Amz-Content-Sha256")
+	if payload == "" {
+		payload = "UNSIGNED-PAYLOAD"
+	}
+	canonical := strings.Join([]string{
+		info.Method,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		headers.String(),
+		query.Get("X-Amz-SignedHeaders"),
+		payload,
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		query.Get("X-Amz-Date"),
+		strings.Join(scope[1:], "/"),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range append(scope[1:], stringToSign) {
+		key = hmacSHA256(key, part)
+	}
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(key)), []byte(signature)) != 1 {
+		return invalid("signature does not match")
+	}
+
+	if signedAt.After(now.Add(presignClockSkew)) {
+		return invalid("signed in the future")
+	}
+	if now.After(info.Expires) {
+		return info, ErrPresignedURLExpired
+	}
+	return info, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func uriEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// sanitizeHost drops default ports, as the SDK signer does.
+func sanitizeHost(u *url.URL) string {
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return u.Host
+	}
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		return host
+	}
+	return u.Host
+}
+
+func presignedBucketKey(u *url.URL, endpointHost string) (bucket, key string) {
+	host := u.Hostname()
+	virtual := ""
+	switch {
+	case endpointHost != "" && strings.HasSuffix(host, "."+endpointHost):
+		virtual = strings.TrimSuffix(host, "."+endpointHost)
+	case endpointHost != "" || net.ParseIP(host) != nil:
+	default:
+		for _, marker := range []string{".s3.", ".s3-"} {
+			if i := strings.Index(host, marker); i > 0 {
+				virtual = host[:i]
+				break
+			}
+		}
+	}
+	p := strings.TrimPrefix(u.Path, "/")
+	if virtual != "" {
+		return virtual, p
+	}
+	bucket, key, _ = strings.Cut(p, "/")
+	return bucket, key
+}
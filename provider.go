@@ -0,0 +1,107 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Provider selects defaults that match the quirks of an S3-compatible
+// service. The zero value keeps the generic behaviour: addressing style
+// chosen from the endpoint and the SDK's checksum defaults.
+type Provider string
+
+const (
+	ProviderGeneric Provider = ""
+	ProviderAWS     Provider = "aws"
+	ProviderMinIO   Provider = "minio"
+	ProviderR2      Provider = "r2"
+	ProviderB2      Provider = "b2"
+	ProviderCeph    Provider = "ceph"
+	ProviderWasabi  Provider = "wasabi"
+)
+
+var ErrNotSupported = errors.New("s3client: operation not supported by provider")
+
+type providerProfile struct {
+	pathStyle bool
+	// minimalChecksums limits checksums to operations that require them,
+	// which also avoids aws-chunked streaming bodies with trailers.
+	minimalChecksums bool
+	region           string
+	unsupported      []string
+}
+
+var providerProfiles = map[Provider]providerProfile{
+	ProviderGeneric: {pathStyle: true},
+	ProviderAWS:     {},
+	ProviderMinIO: {
+		pathStyle:   true,
+		unsupported: []string{"PutBucketAcl", "PutObjectAcl"},
+	},
+	ProviderR2: {
+		pathStyle:        true,
+		minimalChecksums: true,
+		region:           "auto",
+		unsupported: []string{
+			"SelectObjectContent", "PutBucketVersioning", "ListObjectVersions",
+			"PutObjectLockConfiguration", "PutObjectRetention", "PutObjectLegalHold",
+			"RestoreObject", "PutBucketPolicy", "PutBucketAcl", "PutObjectAcl",
+			"GetObjectAttributes",
+		},
+	},
+	ProviderB2: {
+		pathStyle:        true,
+		minimalChecksums: true,
+		unsupported: []string{
+			"SelectObjectContent", "RestoreObject", "GetObjectAttributes",
+			"PutBucketLifecycleConfiguration", "PutBucketReplication", "PutBucketWebsite",
+		},
+	},
+	ProviderCeph: {
+		pathStyle:        true,
+		minimalChecksums: true,
+		unsupported:      []string{"GetObjectAttributes"},
+	},
+	ProviderWasabi: {
+		pathStyle:        true,
+		minimalChecksums: true,
+		unsupported:      []string{"SelectObjectContent", "RestoreObject", "GetObjectAttributes"},
+	},
+}
+
+func profileFor(p Provider) (providerProfile, error) {
+	profile, ok := providerProfiles[p]
+	if !ok {
+		return providerProfile{}, fmt.Errorf("s3client: unknown provider %q", p)
+	}
+	return profile, nil
+}
+
+func (p providerProfile) apply(o *s3.Options) {
+	o.UsePathStyle = p.pathStyle
+	if p.minimalChecksums {
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	}
+	if len(p.unsupported) > 0 {
+		o.APIOptions = append(o.APIOptions, p.rejectUnsupported)
+	}
+}
+
+// rejectUnsupported fails operations the provider is known not to
+// implement before they reach the network.
+func (p providerProfile) rejectUnsupported(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("s3client.ProviderSupport",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if op := middleware.GetOperationName(ctx); slices.Contains(p.unsupported, op) {
+				return middleware.InitializeOutput{}, middleware.Metadata{}, fmt.Errorf("%w: %s", ErrNotSupported, op)
+			}
+			return next.HandleInitialize(ctx, in)
+		}), middleware.After)
+}
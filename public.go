@@ -0,0 +1,187 @@
+package s3client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	allUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// PublicAccess explains whether a bucket is exposed to anyone outside the
+// account. Account-wide public access blocks are not considered.
+type PublicAccess struct {
+	Bucket string
+	Public bool
+	// PolicyPublic is set when the bucket policy grants access to everyone.
+	PolicyPublic bool
+	// ACLGrants are the permissions the bucket ACL grants to all users or
+	// to all authenticated AWS users, as "AllUsers:READ".
+	ACLGrants []string
+	// RestrictPublicBuckets and IgnorePublicACLs are the bucket's public
+	// access block settings that neutralize a public policy or ACL.
+	RestrictPublicBuckets bool
+	IgnorePublicACLs      bool
+	// Err is set by AuditPublicAccess when the bucket could not be checked.
+	Err error
+}
+
+// IsBucketPublic reports whether the policy or ACL of bucket makes it
+// accessible to the public.
+func (c *Client) IsBucketPublic(ctx context.Context, bucket string) (bool, error) {
+	access, err := c.BucketPublicAccess(ctx, bucket)
+	return access.Public, err
+}
+
+// BucketPublicAccess checks the policy status, ACL and public access block
+// of bucket. Backends without GetBucketPolicyStatus have their policy
+// inspected for unconditional statements allowing everyone.
+func (c *Client) BucketPublicAccess(ctx context.Context, bucket string) (PublicAccess, error) {
+	access := PublicAccess{Bucket: bucket}
+	block, err := c.s3Client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: aws.String(bucket)})
+	switch {
+	case err == nil && block.PublicAccessBlockConfiguration != nil:
+		access.RestrictPublicBuckets = aws.ToBool(block.PublicAccessBlockConfiguration.RestrictPublicBuckets)
+		access.IgnorePublicACLs = aws.ToBool(block.PublicAccessBlockConfiguration.IgnorePublicAcls)
+	case err != nil && !isNotImplemented(err) && !hasErrorCode(err, "NoSuchPublicAccessBlockConfiguration"):
+		return access, err
+	}
+
+	status, err := c.s3Client.GetBucketPolicyStatus(ctx, &s3.GetBucketPolicyStatusInput{Bucket: aws.String(bucket)})
+	switch {
+	case err == nil && status.PolicyStatus != nil:
+		access.PolicyPublic = aws.ToBool(status.PolicyStatus.IsPublic)
+	case isNotImplemented(err):
+		policy, err := c.s3Client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+		switch {
+		case err == nil:
+			access.PolicyPublic, err = policyIsPublic(aws.ToString(policy.Policy))
+			if err != nil {
+				return access, err
+			}
+		case !isNotImplemented(err) && !hasErrorCode(err, "NoSuchBucketPolicy"):
+			return access, err
+		}
+	case err != nil && !hasErrorCode(err, "NoSuchBucketPolicy"):
+		return access, err
+	}
+
+	acl, err := c.s3Client.GetBucketAcl(ctx, &s3.GetBucketAclInput{Bucket: aws.String(bucket)})
+	switch {
+	case err == nil:
+		for _, g := range acl.Grants {
+			if g.Grantee == nil || g.Grantee.Type != types.TypeGroup {
+				continue
+			}
+			switch aws.ToString(g.Grantee.URI) {
+			case allUsersGroup:
+				access.ACLGrants = append(access.ACLGrants, "AllUsers:"+string(g.Permission))
+			case authenticatedUsersGroup:
+				access.ACLGrants = append(access.ACLGrants, "AuthenticatedUsers:"+string(g.Permission))
+			}
+		}
+	case !isNotImplemented(err):
+		return access, err
+	}
+
+	access.Public = access.PolicyPublic && !access.RestrictPublicBuckets ||
+		len(access.ACLGrants) > 0 && !access.IgnorePublicACLs
+	return access, nil
+}
+
+// AuditPublicAccess checks every bucket of the account and returns them
+// publicly accessible first. Buckets that cannot be checked are reported
+// with Err rather than failing the audit.
+func (c *Client) AuditPublicAccess(ctx context.Context) ([]PublicAccess, error) {
+	buckets, err := c.ListBucketsDetailed(ctx, ListBucketsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]PublicAccess, len(buckets))
+	err = forEachConcurrent(len(buckets), manifestConcurrency, func(i int) error {
+		access, err := c.BucketPublicAccess(ctx, buckets[i].Name)
+		access.Err = err
+		results[i] = access
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(results, func(a, b PublicAccess) int {
+		switch {
+		case a.Public == b.Public:
+			return 0
+		case a.Public:
+			return -1
+		}
+		return 1
+	})
+	return results, nil
+}
+
+// policyIsPublic reports whether a policy document has an Allow statement
+// for everyone without conditions.
+func policyIsPublic(policy string) (bool, error) {
+	var doc struct {
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return false, fmt.Errorf("s3client: malformed bucket policy: %w", err)
+	}
+	type statement struct {
+		Effect    string          `json:"Effect"`
+		Principal json.RawMessage `json:"Principal"`
+		Condition json.RawMessage `json:"Condition"`
+	}
+	// Statement is an object or an array of objects.
+	var statements []statement
+	if err := json.Unmarshal(doc.Statement, &statements); err != nil {
+		var s statement
+		if err := json.Unmarshal(doc.Statement, &s); err != nil {
+			return false, fmt.Errorf("s3client: malformed bucket policy: %w", err)
+		}
+		statements = []statement{s}
+	}
+	for _, s := range statements {
+		if s.Effect == "Allow" && len(s.Condition) == 0 && principalIsEveryone(s.Principal) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// principalIsEveryone matches "*" and {"AWS": "*"} or {"AWS": ["*"]}.
+func principalIsEveryone(raw json.RawMessage) bool {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s == "*"
+	}
+	var m map[string]json.RawMessage
+	if json.Unmarshal(raw, &m) != nil {
+		return false
+	}
+	principal, ok := m["AWS"]
+	if !ok {
+		return false
+	}
+	if json.Unmarshal(principal, &s) == nil {
+		return s == "*"
+	}
+	var list []string
+	return json.Unmarshal(principal, &list) == nil && slices.Contains(list, "*")
+}
+
+func hasErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
@@ -0,0 +1,273 @@
+package s3client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RecordMode selects whether HTTP traffic is recorded to or replayed from a
+// fixture file.
+type RecordMode string
+
+const (
+	RecordOff    RecordMode = ""
+	RecordRecord RecordMode = "record"
+	RecordReplay RecordMode = "replay"
+)
+
+// RecorderConfig records the client's HTTP interactions for integration
+// tests and replays them without network access. Requests are matched on
+// method, host, path and query, in the order they were recorded.
+type RecorderConfig struct {
+	Mode RecordMode
+	// Path is the fixture file, holding one JSON interaction per line. An
+	// interaction is appended once its response body has been read to the
+	// end or closed; Client.Close closes the file.
+	Path string
+	// Scrub, when set, edits each interaction before it is saved, after the
+	// signature, session token and known credentials have been redacted.
+	// Changing Method, Host or URL breaks replay matching.
+	Scrub func(*Interaction)
+}
+
+// Interaction is one recorded HTTP exchange.
+type Interaction struct {
+	Method string `json:"method"`
+	// Host tells apart virtual-hosted requests to different buckets.
+	Host           string      `json:"host"`
+	URL            string      `json:"url"`
+	RequestHeader  http.Header `json:"request_header,omitempty"`
+	StatusCode     int         `json:"status_code"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   []byte      `json:"response_body,omitempty"`
+}
+
+const redacted = "REDACTED"
+
+var (
+	redactedHeaders = []string{"Authorization", "X-Amz-Security-Token"}
+	redactedParams  = []string{"X-Amz-Credential", "X-Amz-Signature", "X-Amz-Security-Token"}
+	// STS responses carry the issued credentials in the body.
+	redactedElements = regexp.MustCompile(`<(AccessKeyId|SecretAccessKey|SessionToken)>[^<]*</`)
+)
+
+type recorder struct {
+	next    http.RoundTripper
+	cfg     RecorderConfig
+	secrets []string
+
+	mu           sync.Mutex
+	file         *os.File
+	interactions []Interaction
+	used         []bool
+}
+
+func newRecorder(next http.RoundTripper, cfg RecorderConfig, secrets ...string) (*recorder, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("s3client: RecorderConfig.Path is required")
+	}
+	r := &recorder{next: next, cfg: cfg}
+	switch cfg.Mode {
+	case RecordRecord:
+		for _, s := range secrets {
+			if s != "" {
+				r.secrets = append(r.secrets, s)
+			}
+		}
+		f, err := os.Create(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		r.file = f
+	case RecordReplay:
+		f, err := os.Open(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		dec := json.NewDecoder(f)
+		for {
+			var in Interaction
+			if err := dec.Decode(&in); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("s3client: malformed recording %s: %w", cfg.Path, err)
+			}
+			r.interactions = append(r.interactions, in)
+		}
+		r.used = make([]bool, len(r.interactions))
+	default:
+		return nil, fmt.Errorf("s3client: unknown record mode %q", cfg.Mode)
+	}
+	return r, nil
+}
+
+func (r *recorder) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.cfg.Mode == RecordReplay {
+		return r.replay(req)
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	in := &Interaction{
+		Method:         req.Method,
+		Host:           req.URL.Host,
+		URL:            r.scrubURL(req.URL),
+		RequestHeader:  r.scrubHeader(req.Header),
+		StatusCode:     resp.StatusCode,
+		ResponseHeader: r.scrubHeader(resp.Header),
+	}
+	resp.Body = &recordedBody{ReadCloser: resp.Body, r: r, in: in}
+	return resp, nil
+}
+
+// save appends in to the fixture file.
+func (r *recorder) save(in *Interaction, body []byte) error {
+	in.ResponseBody = r.scrubBody(body)
+	if r.cfg.Scrub != nil {
+		r.cfg.Scrub(in)
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return errors.New("s3client: recorder is closed")
+	}
+	_, err = r.file.Write(append(data, '\n'))
+	return err
+}
+
+// recordedBody passes a response body through and saves its interaction
+// with the bytes read once the body ends or is closed.
+type recordedBody struct {
+	io.ReadCloser
+	r     *recorder
+	in    *Interaction
+	buf   bytes.Buffer
+	saved bool
+}
+
+func (b *recordedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		if serr := b.save(); serr != nil {
+			return n, serr
+		}
+	}
+	return n, err
+}
+
+func (b *recordedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if serr := b.save(); serr != nil {
+		return serr
+	}
+	return err
+}
+
+func (b *recordedBody) save() error {
+	if b.saved {
+		return nil
+	}
+	b.saved = true
+	err := b.r.save(b.in, b.buf.Bytes())
+	b.buf = bytes.Buffer{}
+	return err
+}
+
+func (r *recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	target := r.scrubURL(req.URL)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || in.Method != req.Method || in.Host != req.URL.Host || in.URL != target {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+			StatusCode:    in.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.ResponseHeader.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(in.ResponseBody)),
+			ContentLength: int64(len(in.ResponseBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("s3client: no recorded interaction for %s %s%s", req.Method, req.URL.Host, target)
+}
+
+// scrubURL returns the path and canonically ordered query, which with the
+// host identify a request.
+func (r *recorder) scrubURL(u *url.URL) string {
+	query := u.Query()
+	for _, p := range redactedParams {
+		if query.Has(p) {
+			query.Set(p, redacted)
+		}
+	}
+	s := u.EscapedPath()
+	if len(query) > 0 {
+		s += "?" + query.Encode()
+	}
+	return r.scrubString(s)
+}
+
+func (r *recorder) scrubHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range redactedHeaders {
+		if h.Get(name) != "" {
+			h.Set(name, redacted)
+		}
+	}
+	for _, values := range h {
+		for i, v := range values {
+			values[i] = r.scrubString(v)
+		}
+	}
+	return h
+}
+
+func (r *recorder) scrubBody(body []byte) []byte {
+	body = redactedElements.ReplaceAll(body, []byte("<$1>"+redacted+"</"))
+	for _, s := range r.secrets {
+		body = bytes.ReplaceAll(body, []byte(s), []byte(redacted))
+	}
+	return body
+}
+
+func (r *recorder) scrubString(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
@@ -0,0 +1,104 @@
+package s3client
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// JSONLinesReader decodes newline-delimited JSON records from an object.
+// Decode returns io.EOF after the last record.
+type JSONLinesReader struct {
+	*json.Decoder
+	body io.ReadCloser
+}
+
+func (c *Client) NewJSONLinesReader(ctx context.Context, bucket, key string, opts ...CallOption) (*JSONLinesReader, error) {
+	body, err := c.GetObject(ctx, bucket, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesReader{Decoder: json.NewDecoder(bufio.NewReader(body)), body: body}, nil
+}
+
+func (r *JSONLinesReader) Close() error {
+	return r.body.Close()
+}
+
+// JSONLinesWriter streams records to an object, one JSON document per
+// line. The object is only created once Close returns nil.
+type JSONLinesWriter struct {
+	enc *json.Encoder
+	buf *bufio.Writer
+	w   *ObjectWriter
+}
+
+func (c *Client) NewJSONLinesWriter(ctx context.Context, bucket, key string, opts ...CallOption) *JSONLinesWriter {
+	w := c.Bucket(bucket).Object(key).Writer(ctx, opts...)
+	w.ContentType = "application/x-ndjson"
+	buf := bufio.NewWriter(w)
+	return &JSONLinesWriter{enc: json.NewEncoder(buf), buf: buf, w: w}
+}
+
+func (w *JSONLinesWriter) Encode(v any) error {
+	return w.enc.Encode(v)
+}
+
+func (w *JSONLinesWriter) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		return w.w.CloseWithError(err)
+	}
+	return w.w.Close()
+}
+
+// CloseWithError abandons the upload.
+func (w *JSONLinesWriter) CloseWithError(err error) error {
+	return w.w.CloseWithError(err)
+}
+
+// CSVReader reads CSV records from an object. The embedded csv.Reader may
+// be configured before the first Read.
+type CSVReader struct {
+	*csv.Reader
+	body io.ReadCloser
+}
+
+func (c *Client) NewCSVReader(ctx context.Context, bucket, key string, opts ...CallOption) (*CSVReader, error) {
+	body, err := c.GetObject(ctx, bucket, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVReader{Reader: csv.NewReader(bufio.NewReader(body)), body: body}, nil
+}
+
+func (r *CSVReader) Close() error {
+	return r.body.Close()
+}
+
+// CSVWriter streams CSV records to an object. The object is only created
+// once Close returns nil.
+type CSVWriter struct {
+	*csv.Writer
+	w *ObjectWriter
+}
+
+func (c *Client) NewCSVWriter(ctx context.Context, bucket, key string, opts ...CallOption) *CSVWriter {
+	w := c.Bucket(bucket).Object(key).Writer(ctx, opts...)
+	w.ContentType = "text/csv"
+	return &CSVWriter{Writer: csv.NewWriter(w), w: w}
+}
+
+func (w *CSVWriter) Close() error {
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return w.w.CloseWithError(err)
+	}
+	return w.w.Close()
+}
+
+// CloseWithError abandons the upload.
+func (w *CSVWriter) CloseWithError(err error) error {
+	return w.w.CloseWithError(err)
+}
@@ -0,0 +1,83 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// getObject issues the GET and, when read retries are enabled, wraps the
+// body so that mid-stream failures resume with a ranged GET pinned to the
+// original ETag.
+func (c *Client) getObject(ctx context.Context, input *s3.GetObjectInput, o callOptions) (*s3.GetObjectOutput, error) {
+	output, err := c.s3Client.GetObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if o.readRetries > 0 && input.Range == nil && output.ETag != nil {
+		output.Body = &resumableReader{
+			ctx:     ctx,
+			c:       c,
+			input:   *input,
+			etag:    *output.ETag,
+			body:    output.Body,
+			retries: o.readRetries,
+		}
+	}
+	return output, nil
+}
+
+type resumableReader struct {
+	ctx     context.Context
+	c       *Client
+	input   s3.GetObjectInput
+	etag    string
+	body    io.ReadCloser
+	offset  int64
+	retries int
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || errors.Is(err, io.EOF) || r.retries <= 0 || r.ctx.Err() != nil {
+			return n, err
+		}
+		r.retries--
+		r.body.Close()
+		if rerr := r.reopen(); rerr != nil {
+			return n, errors.Join(err, rerr)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *resumableReader) reopen() error {
+	input := r.input
+	input.Range = aws.String(fmt.Sprintf("bytes=%d-", r.offset))
+	input.IfMatch = aws.String(r.etag)
+	output, err := r.c.s3Client.GetObject(r.ctx, &input)
+	if err != nil {
+		r.body = io.NopCloser(eofReader{})
+		return err
+	}
+	r.body = output.Body
+	return nil
+}
+
+func (r *resumableReader) Close() error {
+	return r.body.Close()
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
@@ -0,0 +1,311 @@
+package s3client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// rotateCopyPart is the range size of multipart copies for objects too
+// large for CopyObject.
+const rotateCopyPart = 512 << 20
+
+// EncryptionKey selects SSE-KMS with KMSKeyID, a key ID, ARN or alias, or
+// SSE-C with a 256-bit CustomerKey.
+type EncryptionKey struct {
+	KMSKeyID    string
+	CustomerKey []byte
+}
+
+type RotateOptions struct {
+	// OldCustomerKey decrypts objects currently encrypted with SSE-C.
+	OldCustomerKey []byte
+	// Concurrency defaults to 8.
+	Concurrency int
+	// Progress, if set, is called after every object.
+	Progress func(RotateProgress)
+}
+
+type RotateProgress struct {
+	Total   int64
+	Rotated int64
+	Skipped int64
+	Failed  int64
+	Key     string
+}
+
+// RotateEncryption re-encrypts every object under prefix with key by
+// copying it onto itself, keeping metadata and tags. Objects already
+// encrypted with key are skipped, so an interrupted rotation can simply be
+// run again. In versioned buckets the previous versions keep the old key.
+// Every object is attempted and failures are joined into one error.
+func (c *Client) RotateEncryption(ctx context.Context, bucket, prefix string, key EncryptionKey, opts RotateOptions) (RotateProgress, error) {
+	if (key.KMSKeyID == "") == (key.CustomerKey == nil) {
+		return RotateProgress{}, errors.New("s3client: exactly one of KMSKeyID and CustomerKey must be set")
+	}
+	if key.CustomerKey != nil && len(key.CustomerKey) != 32 {
+		return RotateProgress{}, errors.New("s3client: SSE-C keys must be 256 bits")
+	}
+	if key.KMSKeyID != "" {
+		arn, err := c.resolveKMSKey(ctx, key.KMSKeyID)
+		if err != nil {
+			return RotateProgress{}, err
+		}
+		key.KMSKeyID = arn
+	}
+	var objects []types.Object
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		if !strings.HasSuffix(aws.ToString(obj.Key), "/") {
+			objects = append(objects, obj)
+		}
+		return nil
+	})
+	if err != nil {
+		return RotateProgress{}, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = manifestConcurrency
+	}
+	var (
+		mu       sync.Mutex
+		progress = RotateProgress{Total: int64(len(objects))}
+		errs     []error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+	)
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(objKey string) {
+			defer func() { <-sem; wg.Done() }()
+			skipped, err := c.rotateObject(ctx, bucket, objKey, key, opts.OldCustomerKey)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				progress.Failed++
+				errs = append(errs, fmt.Errorf("%s: %w", objKey, err))
+			case skipped:
+				progress.Skipped++
+			default:
+				progress.Rotated++
+			}
+			progress.Key = objKey
+			if opts.Progress != nil {
+				opts.Progress(progress)
+			}
+		}(aws.ToString(obj.Key))
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return progress, errors.Join(errs...)
+}
+
+type ssecParams struct {
+	alg, key, md5 *string
+}
+
+func newSSECParams(key []byte) ssecParams {
+	if key == nil {
+		return ssecParams{}
+	}
+	sum := md5.Sum(key)
+	return ssecParams{
+		alg: aws.String("AES256"),
+		key: aws.String(base64.StdEncoding.EncodeToString(key)),
+		md5: aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+	}
+}
+
+// rotateObject reports whether the object already used key.
+func (c *Client) rotateObject(ctx context.Context, bucket, objKey string, key EncryptionKey, oldCustomerKey []byte) (bool, error) {
+	newC := newSSECParams(key.CustomerKey)
+	// A HEAD without a key fails for SSE-C objects, which are then tried
+	// with the new key, to skip them once rotated, and with the old one.
+	// SSE-C headers sent for other objects fail the request.
+	var oldC ssecParams
+	head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(objKey),
+	})
+	if err != nil && !isNotFound(err) {
+		if key.CustomerKey != nil {
+			_, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket:               aws.String(bucket),
+				Key:                  aws.String(objKey),
+				SSECustomerAlgorithm: newC.alg,
+				SSECustomerKey:       newC.key,
+				SSECustomerKeyMD5:    newC.md5,
+			})
+			if err == nil {
+				return true, nil
+			}
+		}
+		if oldCustomerKey != nil {
+			oldC = newSSECParams(oldCustomerKey)
+			head, err = c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket:               aws.String(bucket),
+				Key:                  aws.String(objKey),
+				SSECustomerAlgorithm: oldC.alg,
+				SSECustomerKey:       oldC.key,
+				SSECustomerKeyMD5:    oldC.md5,
+			})
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	if key.KMSKeyID != "" && head.ServerSideEncryption == types.ServerSideEncryptionAwsKms && kmsKeyMatches(aws.ToString(head.SSEKMSKeyId), key.KMSKeyID) {
+		return true, nil
+	}
+
+	c.invalidate(bucket, objKey)
+	size := aws.ToInt64(head.ContentLength)
+	if size <= maxPartSize {
+		input := &s3.CopyObjectInput{
+			Bucket:                         aws.String(bucket),
+			Key:                            aws.String(objKey),
+			CopySource:                     aws.String(copySource(bucket, objKey)),
+			MetadataDirective:              types.MetadataDirectiveCopy,
+			StorageClass:                   head.StorageClass,
+			CopySourceIfMatch:              head.ETag,
+			CopySourceSSECustomerAlgorithm: oldC.alg,
+			CopySourceSSECustomerKey:       oldC.key,
+			CopySourceSSECustomerKeyMD5:    oldC.md5,
+			SSECustomerAlgorithm:           newC.alg,
+			SSECustomerKey:                 newC.key,
+			SSECustomerKeyMD5:              newC.md5,
+		}
+		if key.KMSKeyID != "" {
+			input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+			input.SSEKMSKeyId = aws.String(key.KMSKeyID)
+		}
+		_, err := c.s3Client.CopyObject(ctx, input)
+		return false, err
+	}
+	return false, c.rotateMultipart(ctx, bucket, objKey, size, head, key, newC, oldC)
+}
+
+// rotateMultipart copies objects above 5 GiB in ranges, carrying over the
+// headers, metadata and tags a multipart upload does not copy by itself.
+func (c *Client) rotateMultipart(ctx context.Context, bucket, objKey string, size int64, head *s3.HeadObjectOutput, key EncryptionKey, newC, oldC ssecParams) error {
+	ctx, done, err := c.drain.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	tags, err := c.GetObjectTags(ctx, bucket, objKey)
+	if err != nil && !isNotImplemented(err) {
+		return err
+	}
+	create := &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(objKey),
+		ContentType:          head.ContentType,
+		ContentEncoding:      head.ContentEncoding,
+		ContentDisposition:   head.ContentDisposition,
+		ContentLanguage:      head.ContentLanguage,
+		CacheControl:         head.CacheControl,
+		Metadata:             head.Metadata,
+		StorageClass:         head.StorageClass,
+		SSECustomerAlgorithm: newC.alg,
+		SSECustomerKey:       newC.key,
+		SSECustomerKeyMD5:    newC.md5,
+	}
+	if len(tags) > 0 {
+		values := url.Values{}
+		for k, v := range tags {
+			values.Set(k, v)
+		}
+		create.Tagging = aws.String(values.Encode())
+	}
+	if key.KMSKeyID != "" {
+		create.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		create.SSEKMSKeyId = aws.String(key.KMSKeyID)
+	}
+	upload, err := c.s3Client.CreateMultipartUpload(ctx, create)
+	if err != nil {
+		return err
+	}
+
+	var parts []types.CompletedPart
+	for off := int64(0); off < size; off += rotateCopyPart {
+		n := min(rotateCopyPart, size-off)
+		num := aws.Int32(int32(len(parts) + 1))
+		output, err := c.s3Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:                         aws.String(bucket),
+			Key:                            aws.String(objKey),
+			UploadId:                       upload.UploadId,
+			PartNumber:                     num,
+			CopySource:                     aws.String(copySource(bucket, objKey)),
+			CopySourceRange:                aws.String(fmt.Sprintf("bytes=%d-%d", off, off+n-1)),
+			CopySourceIfMatch:              head.ETag,
+			CopySourceSSECustomerAlgorithm: oldC.alg,
+			CopySourceSSECustomerKey:       oldC.key,
+			CopySourceSSECustomerKeyMD5:    oldC.md5,
+			SSECustomerAlgorithm:           newC.alg,
+			SSECustomerKey:                 newC.key,
+			SSECustomerKeyMD5:              newC.md5,
+		})
+		if err != nil {
+			c.abortMultipart(ctx, bucket, objKey, upload.UploadId)
+			return err
+		}
+		parts = append(parts, types.CompletedPart{ETag: output.CopyPartResult.ETag, PartNumber: num})
+	}
+	_, err = c.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(objKey),
+		UploadId:             upload.UploadId,
+		MultipartUpload:      &types.CompletedMultipartUpload{Parts: parts},
+		SSECustomerAlgorithm: newC.alg,
+		SSECustomerKey:       newC.key,
+		SSECustomerKeyMD5:    newC.md5,
+	})
+	if err != nil {
+		c.abortMultipart(ctx, bucket, objKey, upload.UploadId)
+	}
+	return err
+}
+
+// resolveKMSKey turns an alias into the ARN of its key, which is what S3
+// reports for objects encrypted with it.
+func (c *Client) resolveKMSKey(ctx context.Context, keyID string) (string, error) {
+	if !strings.HasPrefix(keyID, "alias/") && !strings.Contains(keyID, ":alias/") {
+		return keyID, nil
+	}
+	client := kms.New(kms.Options{
+		Region:      c.cfg.Region,
+		Credentials: c.creds.cache,
+		HTTPClient:  c.s3Client.Options().HTTPClient,
+	})
+	output, err := client.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return "", fmt.Errorf("s3client: resolving KMS alias %s: %w", keyID, err)
+	}
+	return aws.ToString(output.KeyMetadata.Arn), nil
+}
+
+// kmsKeyMatches compares a key ID or ARN reported by S3 with the one
+// requested, which may be a bare key ID.
+func kmsKeyMatches(got, want string) bool {
+	return got == want || strings.HasSuffix(got, "/"+want) || strings.HasSuffix(got, ":"+want)
+}
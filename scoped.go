@@ -0,0 +1,151 @@
+package s3client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+var defaultScopedActions = []string{
+	"s3:PutObject",
+	"s3:AbortMultipartUpload",
+	"s3:ListMultipartUploadParts",
+}
+
+type ScopedCredentialsOptions struct {
+	// Duration defaults to one hour. STS enforces a minimum of 15 minutes.
+	Duration time.Duration
+	// RoleARN is assumed with AssumeRole when set. Otherwise
+	// GetFederationToken is called, which needs IAM user credentials.
+	RoleARN string
+	// SessionName names the role session or federated user. It defaults to
+	// "s3client".
+	SessionName string
+	// Actions granted on objects under the prefix. They default to the
+	// actions needed for single and multipart uploads.
+	Actions []string
+	// AllowList also grants s3:ListBucket restricted to the prefix.
+	AllowList bool
+	// Endpoint overrides the STS endpoint. It defaults to AWS for AWS
+	// endpoints and to the client endpoint otherwise, as MinIO serves STS
+	// alongside S3.
+	Endpoint string
+}
+
+// ScopedCredentials are temporary credentials limited to Bucket/Prefix,
+// with the settings a remote client needs to use them.
+type ScopedCredentials struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+	Region          string    `json:"region"`
+	Endpoint        string    `json:"endpoint,omitempty"`
+	Bucket          string    `json:"bucket"`
+	Prefix          string    `json:"prefix"`
+}
+
+// IssueScopedCredentials mints short-lived credentials whose inline session
+// policy only allows opts.Actions on objects below bucket/prefix, as an
+// alternative to presigning every request of a multi-file upload session.
+func (c *Client) IssueScopedCredentials(ctx context.Context, bucket, prefix string, opts ScopedCredentialsOptions) (ScopedCredentials, error) {
+	if opts.Duration <= 0 {
+		opts.Duration = time.Hour
+	}
+	if opts.SessionName == "" {
+		opts.SessionName = "s3client"
+	}
+	if len(opts.Actions) == 0 {
+		opts.Actions = defaultScopedActions
+	}
+	policy, err := scopedPolicy(bucket, prefix, opts)
+	if err != nil {
+		return ScopedCredentials{}, err
+	}
+
+	endpoint := opts.Endpoint
+	if endpoint == "" && !isAWSEndpoint(c.cfg.Endpoint) {
+		endpoint = c.cfg.Endpoint
+	}
+	stsClient := sts.New(sts.Options{
+		Region:      c.cfg.Region,
+		Credentials: c.creds.cache,
+		HTTPClient:  c.s3Client.Options().HTTPClient,
+	}, func(o *sts.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+
+	seconds := aws.Int32(int32(opts.Duration / time.Second))
+	var creds *ststypes.Credentials
+	if opts.RoleARN != "" {
+		output, err := stsClient.AssumeRole(ctx, &sts.AssumeRoleInput{
+			RoleArn:         aws.String(opts.RoleARN),
+			RoleSessionName: aws.String(opts.SessionName),
+			Policy:          aws.String(policy),
+			DurationSeconds: seconds,
+		})
+		if err != nil {
+			return ScopedCredentials{}, err
+		}
+		creds = output.Credentials
+	} else {
+		output, err := stsClient.GetFederationToken(ctx, &sts.GetFederationTokenInput{
+			Name:            aws.String(opts.SessionName),
+			Policy:          aws.String(policy),
+			DurationSeconds: seconds,
+		})
+		if err != nil {
+			return ScopedCredentials{}, err
+		}
+		creds = output.Credentials
+	}
+	if creds == nil {
+		return ScopedCredentials{}, errors.New("s3client: STS returned no credentials")
+	}
+	return ScopedCredentials{
+		AccessKeyID:     aws.ToString(creds.AccessKeyId),
+		SecretAccessKey: aws.ToString(creds.SecretAccessKey),
+		SessionToken:    aws.ToString(creds.SessionToken),
+		Expiration:      aws.ToTime(creds.Expiration),
+		Region:          c.cfg.Region,
+		Endpoint:        c.cfg.Endpoint,
+		Bucket:          bucket,
+		Prefix:          prefix,
+	}, nil
+}
+
+func scopedPolicy(bucket, prefix string, opts ScopedCredentialsOptions) (string, error) {
+	type statement struct {
+		Effect    string         `json:"Effect"`
+		Action    []string       `json:"Action"`
+		Resource  string         `json:"Resource"`
+		Condition map[string]any `json:"Condition,omitempty"`
+	}
+	statements := []statement{{
+		Effect:   "Allow",
+		Action:   opts.Actions,
+		Resource: "arn:aws:s3:::" + bucket + "/" + prefix + "*",
+	}}
+	if opts.AllowList {
+		statements = append(statements, statement{
+			Effect:   "Allow",
+			Action:   []string{"s3:ListBucket"},
+			Resource: "arn:aws:s3:::" + bucket,
+			Condition: map[string]any{
+				"StringLike": map[string]string{"s3:prefix": prefix + "*"},
+			},
+		})
+	}
+	data, err := json.Marshal(map[string]any{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	})
+	return string(data), err
+}
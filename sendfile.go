@@ -0,0 +1,38 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// fileSection is the part of a local file a request body consists of. The
+// transfer manager sends file-backed parts as sections of the *os.File.
+type fileSection struct {
+	file *os.File
+	off  int64
+	n    int64
+}
+
+type fileSectionKey struct{}
+
+// addFileSection marks requests whose final body is a plain file section
+// so the sendfile transport can hand the file to the kernel. Bodies the SDK
+// has wrapped, such as aws-chunked uploads, are left alone.
+func addFileSection(stack *middleware.Stack) error {
+	return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("s3client.FileSection",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if req, ok := in.Request.(*smithyhttp.Request); ok {
+				if sr, ok := req.GetStream().(*io.SectionReader); ok {
+					outer, off, n := sr.Outer()
+					if f, ok := outer.(*os.File); ok && n > 0 && req.ContentLength == n {
+						ctx = context.WithValue(ctx, fileSectionKey{}, fileSection{file: f, off: off, n: n})
+					}
+				}
+			}
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+}
@@ -0,0 +1,84 @@
+package s3client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+// sendfileTransport sends file-backed upload bodies straight from the file,
+// which net/http passes to sendfile(2) instead of copying the data through
+// user space. This only applies to plain HTTP, as TLS encrypts in user
+// space, and only saves the copy made for sending: SigV4 still hashes the
+// payload over plain HTTP, reading the part once before it is sent.
+type sendfileTransport struct {
+	next http.RoundTripper
+}
+
+func newSendfileTransport(next http.RoundTripper) http.RoundTripper {
+	return &sendfileTransport{next: next}
+}
+
+func (t *sendfileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s, ok := req.Context().Value(fileSectionKey{}).(fileSection)
+	if !ok || req.URL.Scheme != "http" || req.ContentLength != s.n {
+		return t.next.RoundTrip(req)
+	}
+	// Parts are sent concurrently, so each request reads through its own
+	// descriptor and file offset.
+	open := func() (io.ReadCloser, error) {
+		f, err := os.Open(fmt.Sprintf("/proc/self/fd/%d", s.file.Fd()))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(s.off, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &sectionBody{file: f, end: s.off + s.n}, nil
+	}
+	body, err := open()
+	if err != nil {
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	r.GetBody = open
+	return t.next.RoundTrip(r)
+}
+
+// sectionBody reads a file up to end. net/http copies ContentLength bytes
+// of it through an io.LimitedReader, which the connection hands to
+// sendfile(2) through SyscallConn; that moves the file offset without
+// calling Read, so Read bounds itself by the offset rather than by a count.
+type sectionBody struct {
+	file *os.File
+	end  int64
+}
+
+func (b *sectionBody) Read(p []byte) (int, error) {
+	pos, err := b.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if pos >= b.end {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.end-pos {
+		p = p[:b.end-pos]
+	}
+	return b.file.Read(p)
+}
+
+func (b *sectionBody) Close() error {
+	return b.file.Close()
+}
+
+func (b *sectionBody) SyscallConn() (syscall.RawConn, error) {
+	return b.file.SyscallConn()
+}
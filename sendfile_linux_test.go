@@ -0,0 +1,138 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// multipartServer implements the multipart upload calls of UploadFile for
+// a single upload and keeps the assembled object.
+type multipartServer struct {
+	mu     sync.Mutex
+	parts  map[int][]byte
+	object []byte
+}
+
+func (m *multipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		m.mu.Lock()
+		m.parts = map[int][]byte{}
+		m.mu.Unlock()
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>u1</UploadId></InitiateMultipartUploadResult>`)
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			http.Error(w, "short body", http.StatusBadRequest)
+			return
+		}
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		m.mu.Lock()
+		m.parts[n] = data
+		m.mu.Unlock()
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, n))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		io.Copy(io.Discard, r.Body)
+		m.mu.Lock()
+		numbers := make([]int, 0, len(m.parts))
+		for n := range m.parts {
+			numbers = append(numbers, n)
+		}
+		slices.Sort(numbers)
+		m.object = nil
+		for _, n := range numbers {
+			m.object = append(m.object, m.parts[n]...)
+		}
+		m.mu.Unlock()
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"done"</ETag></CompleteMultipartUploadResult>`)
+	default:
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+	}
+}
+
+func newMultipartClient(tb testing.TB, sendfile bool) (*Client, *multipartServer) {
+	tb.Helper()
+	srv := &multipartServer{}
+	ts := httptest.NewServer(srv)
+	tb.Cleanup(ts.Close)
+	tb.Setenv("AWS_CA_BUNDLE", "")
+	c, err := New(Config{
+		Endpoint:        ts.URL,
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		AddressingStyle: AddressingPath,
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { c.Close() })
+	if !sendfile {
+		opts := c.s3Client.Options()
+		hc := *opts.HTTPClient.(*http.Client)
+		hc.Transport = hc.Transport.(*sendfileTransport).next
+		opts.HTTPClient = &hc
+		c.s3Client = s3.New(opts)
+		c.uploader.S3 = c.s3Client
+	}
+	return c, srv
+}
+
+func writeRandomFile(tb testing.TB, size int) (string, []byte) {
+	tb.Helper()
+	data := make([]byte, size)
+	rand.Read(data)
+	name := filepath.Join(tb.TempDir(), "data")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return name, data
+}
+
+func TestUploadFileSendfileParts(t *testing.T) {
+	c, srv := newMultipartClient(t, true)
+	// Three parts, the last one shorter, so every part but the last ends
+	// before the end of the file.
+	name, data := writeRandomFile(t, 2*minPartSize+minPartSize/2)
+	if err := c.UploadFile(context.Background(), "b", "k", name, WithPartSize(minPartSize)); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.parts) != 3 {
+		t.Errorf("uploaded %d parts, want 3", len(srv.parts))
+	}
+	if !bytes.Equal(srv.object, data) {
+		t.Errorf("uploaded object differs from the file: %d bytes, want %d", len(srv.object), len(data))
+	}
+}
+
+func BenchmarkUploadFile(b *testing.B) {
+	for _, bc := range []struct {
+		name     string
+		sendfile bool
+	}{{"copy", false}, {"sendfile", true}} {
+		b.Run(bc.name, func(b *testing.B) {
+			c, _ := newMultipartClient(b, bc.sendfile)
+			name, data := writeRandomFile(b, 4*minPartSize)
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				if err := c.UploadFile(context.Background(), "b", "k", name, WithPartSize(minPartSize)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package s3client
+
+import "net/http"
+
+func newSendfileTransport(next http.RoundTripper) http.RoundTripper {
+	return next
+}
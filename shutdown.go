@@ -0,0 +1,143 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ErrShutdown is returned for operations started after Shutdown.
+var ErrShutdown = errors.New("s3client: client is shut down")
+
+// drainer tracks in-flight operations so Shutdown can wait for them. It is
+// shared by a client and the clones made with With, which send their
+// requests through the same middleware.
+type drainer struct {
+	mu       sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
+	abort    context.Context
+	cancel   context.CancelFunc
+}
+
+func newDrainer() *drainer {
+	d := &drainer{}
+	d.abort, d.cancel = context.WithCancel(context.Background())
+	return d
+}
+
+type drainKey struct{}
+
+// drainMark is the drainKey value of a context: the drainers it is
+// registered with, or all of them for housekeeping.
+type drainMark struct {
+	all      bool
+	drainers []*drainer
+}
+
+func (d *drainer) tracks(ctx context.Context) bool {
+	mark, _ := ctx.Value(drainKey{}).(drainMark)
+	return mark.all || slices.Contains(mark.drainers, d)
+}
+
+// begin registers an operation. Operations nested in one already
+// registered, such as the parts of a multipart upload, are part of it and
+// pass even after Shutdown was called. The returned context is canceled
+// when Shutdown gives up waiting.
+func (d *drainer) begin(ctx context.Context) (context.Context, func(), error) {
+	if d.tracks(ctx) {
+		return ctx, func() {}, nil
+	}
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ctx, nil, ErrShutdown
+	}
+	d.inflight.Add(1)
+	d.mu.Unlock()
+
+	mark, _ := ctx.Value(drainKey{}).(drainMark)
+	mark.drainers = append(slices.Clip(mark.drainers), d)
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, drainKey{}, mark))
+	stop := context.AfterFunc(d.abort, cancel)
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			stop()
+			cancel()
+			d.inflight.Done()
+		})
+	}, nil
+}
+
+// addMiddleware registers every request. A GetObject stays in flight until
+// its body is closed. The event stream of SelectObjectContent ends with the
+// context of the call, so it is only refused after Shutdown, not tracked.
+func (d *drainer) addMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("s3client.Shutdown",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if middleware.GetOperationName(ctx) == "SelectObjectContent" {
+				if d.isClosed() && !d.tracks(ctx) {
+					return middleware.InitializeOutput{}, middleware.Metadata{}, ErrShutdown
+				}
+				return next.HandleInitialize(ctx, in)
+			}
+			ctx, done, err := d.begin(ctx)
+			if err != nil {
+				return middleware.InitializeOutput{}, middleware.Metadata{}, err
+			}
+			out, md, err := next.HandleInitialize(ctx, in)
+			if output, ok := out.Result.(*s3.GetObjectOutput); ok && err == nil && output.Body != nil {
+				output.Body = &cancelOnClose{ReadCloser: output.Body, cancel: done}
+			} else {
+				done()
+			}
+			return out, md, err
+		}), middleware.Before)
+}
+
+func (d *drainer) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// Shutdown stops the client for a graceful exit: operations started
+// afterwards fail with ErrShutdown, requests and transfers in flight are
+// waited for, and then background work is stopped as by Close, flushing
+// the audit sink. When ctx ends first, the remaining operations are
+// canceled and Shutdown waits for them to return, multipart uploads
+// included, before closing the client and returning ctx.Err(). Clients
+// made with With share the in-flight operations of their parent, so
+// shutting down either stops both.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.drain.mu.Lock()
+	c.drain.closed = true
+	c.drain.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.drain.inflight.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		c.drain.cancel()
+		<-drained
+	}
+	c.Close()
+	return err
+}
+
+// bypassShutdown marks ctx as belonging to the client's own housekeeping,
+// which keeps running after Shutdown.
+func bypassShutdown(ctx context.Context) context.Context {
+	return context.WithValue(ctx, drainKey{}, drainMark{all: true})
+}
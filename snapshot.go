@@ -0,0 +1,129 @@
+package s3client
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Snapshot is a point-in-time listing of a prefix, sorted by key.
+type Snapshot struct {
+	Bucket  string          `json:"bucket"`
+	Prefix  string          `json:"prefix"`
+	Taken   time.Time       `json:"taken"`
+	Objects []SnapshotEntry `json:"-"`
+}
+
+type SnapshotEntry struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// SnapshotListing lists every object under prefix.
+func (c *Client) SnapshotListing(ctx context.Context, bucket, prefix string) (Snapshot, error) {
+	s := Snapshot{Bucket: bucket, Prefix: prefix, Taken: time.Now().UTC()}
+	err := c.listFiltered(ctx, bucket, prefix, ListOptions{}, func(info ObjectInfo) error {
+		s.Objects = append(s.Objects, SnapshotEntry{
+			Key:          info.Key,
+			Size:         info.Size,
+			ETag:         strings.Trim(info.ETag, `"`),
+			LastModified: info.LastModified,
+		})
+		return nil
+	})
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return s, nil
+}
+
+// WriteSnapshot stores s as JSON lines, a header followed by one line per
+// object, so large snapshots are streamed rather than held as one document.
+func (c *Client) WriteSnapshot(ctx context.Context, bucket, key string, s Snapshot) error {
+	w := c.NewJSONLinesWriter(ctx, bucket, key)
+	if err := w.Encode(s); err != nil {
+		return w.CloseWithError(err)
+	}
+	for _, e := range s.Objects {
+		if err := w.Encode(e); err != nil {
+			return w.CloseWithError(err)
+		}
+	}
+	return w.Close()
+}
+
+func (c *Client) ReadSnapshot(ctx context.Context, bucket, key string) (Snapshot, error) {
+	r, err := c.NewJSONLinesReader(ctx, bucket, key)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer r.Close()
+	var s Snapshot
+	if err := r.Decode(&s); err != nil {
+		return Snapshot{}, err
+	}
+	for {
+		var e SnapshotEntry
+		if err := r.Decode(&e); err == io.EOF {
+			return s, nil
+		} else if err != nil {
+			return Snapshot{}, err
+		}
+		s.Objects = append(s.Objects, e)
+	}
+}
+
+type ListingDiff struct {
+	Added   []SnapshotEntry
+	Removed []SnapshotEntry
+	Changed []SnapshotChange
+}
+
+type SnapshotChange struct {
+	Old SnapshotEntry
+	New SnapshotEntry
+}
+
+// Empty reports whether the listings hold the same objects.
+func (d ListingDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffListings compares two snapshots key by key. An object has changed
+// when its size or ETag differs; a new modification time alone, as left by
+// rewriting identical content, is not a change.
+func DiffListings(old, new Snapshot) ListingDiff {
+	a, b := sortedEntries(old.Objects), sortedEntries(new.Objects)
+	var d ListingDiff
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && a[0].Key < b[0].Key:
+			d.Removed = append(d.Removed, a[0])
+			a = a[1:]
+		case len(a) == 0 || b[0].Key < a[0].Key:
+			d.Added = append(d.Added, b[0])
+			b = b[1:]
+		default:
+			if a[0].Size != b[0].Size || a[0].ETag != b[0].ETag {
+				d.Changed = append(d.Changed, SnapshotChange{Old: a[0], New: b[0]})
+			}
+			a, b = a[1:], b[1:]
+		}
+	}
+	return d
+}
+
+func sortedEntries(entries []SnapshotEntry) []SnapshotEntry {
+	byKey := func(x, y SnapshotEntry) int { return cmp.Compare(x.Key, y.Key) }
+	if slices.IsSortedFunc(entries, byKey) {
+		return entries
+	}
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, byKey)
+	return entries
+}
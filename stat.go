@@ -0,0 +1,80 @@
+package s3client
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type ObjectInfo struct {
+	Key             string
+	Size            int64
+	ETag            string
+	LastModified    time.Time
+	ContentType     string
+	ContentEncoding string
+	StorageClass    string
+	VersionID       string
+	Metadata        map[string]string
+}
+
+func (c *Client) StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	if c.cache != nil {
+		if info, ok := c.cache.stat(bucket, key); ok {
+			return info, nil
+		}
+	}
+	head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info := headObjectInfo(key, head)
+	if c.cache != nil {
+		c.cache.putStat(bucket, key, info)
+	}
+	return info, nil
+}
+
+func headObjectInfo(key string, head *s3.HeadObjectOutput) ObjectInfo {
+	return ObjectInfo{
+		Key:             key,
+		Size:            aws.ToInt64(head.ContentLength),
+		ETag:            aws.ToString(head.ETag),
+		LastModified:    aws.ToTime(head.LastModified),
+		ContentType:     aws.ToString(head.ContentType),
+		ContentEncoding: aws.ToString(head.ContentEncoding),
+		StorageClass:    string(head.StorageClass),
+		VersionID:       aws.ToString(head.VersionId),
+		Metadata:        head.Metadata,
+	}
+}
+
+func getObjectInfo(key string, output *s3.GetObjectOutput) ObjectInfo {
+	return ObjectInfo{
+		Key:             key,
+		Size:            aws.ToInt64(output.ContentLength),
+		ETag:            aws.ToString(output.ETag),
+		LastModified:    aws.ToTime(output.LastModified),
+		ContentType:     aws.ToString(output.ContentType),
+		ContentEncoding: aws.ToString(output.ContentEncoding),
+		StorageClass:    string(output.StorageClass),
+		VersionID:       aws.ToString(output.VersionId),
+		Metadata:        output.Metadata,
+	}
+}
+
+func objectInfo(obj types.Object) ObjectInfo {
+	return ObjectInfo{
+		Key:          aws.ToString(obj.Key),
+		Size:         aws.ToInt64(obj.Size),
+		ETag:         aws.ToString(obj.ETag),
+		LastModified: aws.ToTime(obj.LastModified),
+		StorageClass: string(obj.StorageClass),
+	}
+}
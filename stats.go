@@ -0,0 +1,123 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Stats holds counters accumulated since the client was created or since
+// the last ResetStats.
+type Stats struct {
+	Since time.Time
+	// Requests counts operations by name; presigning is not counted.
+	Requests map[string]int64
+	Errors   int64
+	// Retries counts HTTP attempts beyond the first of each operation.
+	Retries         int64
+	BytesUploaded   int64
+	BytesDownloaded int64
+}
+
+type statsCounters struct {
+	mu         sync.Mutex
+	since      time.Time
+	requests   map[string]int64
+	errors     atomic.Int64
+	attempts   atomic.Int64
+	total      atomic.Int64
+	uploaded   atomic.Int64
+	downloaded atomic.Int64
+}
+
+func newStatsCounters() *statsCounters {
+	return &statsCounters{since: time.Now(), requests: map[string]int64{}}
+}
+
+func (c *Client) Stats() Stats {
+	s := c.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		Since:           s.since,
+		Requests:        maps.Clone(s.requests),
+		Errors:          s.errors.Load(),
+		Retries:         max(s.attempts.Load()-s.total.Load(), 0),
+		BytesUploaded:   s.uploaded.Load(),
+		BytesDownloaded: s.downloaded.Load(),
+	}
+}
+
+// ResetStats zeroes the counters. Requests in flight are counted towards
+// the new period when they finish.
+func (c *Client) ResetStats() {
+	s := c.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.since = time.Now()
+	clear(s.requests)
+	for _, v := range []*atomic.Int64{&s.errors, &s.attempts, &s.total, &s.uploaded, &s.downloaded} {
+		v.Store(0)
+	}
+}
+
+func (s *statsCounters) addMiddleware(stack *middleware.Stack) error {
+	err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("s3client.Stats",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if presign, _ := ctx.Value(auditPresignKey).(bool); presign {
+				return next.HandleInitialize(ctx, in)
+			}
+			out, md, err := next.HandleInitialize(ctx, in)
+			s.mu.Lock()
+			s.requests[middleware.GetOperationName(ctx)]++
+			s.mu.Unlock()
+			s.total.Add(1)
+			if err != nil {
+				s.errors.Add(1)
+			}
+			return out, md, err
+		}), middleware.After)
+	if err != nil {
+		return err
+	}
+	if _, ok := stack.Finalize.Get("Retry"); ok {
+		err := stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("s3client.StatsAttempts",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				s.attempts.Add(1)
+				if req, ok := in.Request.(*smithyhttp.Request); ok && req.ContentLength > 0 {
+					s.uploaded.Add(req.ContentLength)
+				}
+				return next.HandleFinalize(ctx, in)
+			}), "Retry", middleware.After)
+		if err != nil {
+			return err
+		}
+	}
+	// Added last, so it wraps the raw body before the operation's
+	// deserializer reads it or hands it to the caller.
+	return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("s3client.StatsBody",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+			out, md, err := next.HandleDeserialize(ctx, in)
+			if resp, ok := out.RawResponse.(*smithyhttp.Response); ok && resp.Body != nil {
+				resp.Body = &countingBody{ReadCloser: resp.Body, n: &s.downloaded}
+			}
+			return out, md, err
+		}), middleware.After)
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
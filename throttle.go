@@ -0,0 +1,104 @@
+package s3client
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+)
+
+type ThrottleStats struct {
+	// Requests counts operations sent; Attempts counts HTTP attempts
+	// including SDK retries.
+	Requests  int64
+	Attempts  int64
+	Throttled int64
+	// Retries counts both SDK retries and the client-level retries done by
+	// bulk helpers such as DeleteObjects and UploadMany.
+	Retries int64
+}
+
+type throttleCounters struct {
+	requests  atomic.Int64
+	attempts  atomic.Int64
+	throttled atomic.Int64
+	retries   atomic.Int64
+}
+
+func (c *Client) ThrottleStats() ThrottleStats {
+	t := c.throttle
+	requests, attempts := t.requests.Load(), t.attempts.Load()
+	return ThrottleStats{
+		Requests:  requests,
+		Attempts:  attempts,
+		Throttled: t.throttled.Load(),
+		Retries:   max(attempts-requests, 0) + t.retries.Load(),
+	}
+}
+
+var throttleCheck = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+func isThrottle(err error) bool {
+	return err != nil && throttleCheck.IsErrorThrottle(err) == aws.TrueTernary
+}
+
+func (t *throttleCounters) addMiddleware(stack *middleware.Stack) error {
+	if _, ok := stack.Finalize.Get("Retry"); !ok {
+		return nil
+	}
+	err := stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("s3client.CountRequests",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			t.requests.Add(1)
+			return next.HandleFinalize(ctx, in)
+		}), "Retry", middleware.Before)
+	if err != nil {
+		return err
+	}
+	return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("s3client.CountAttempts",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			t.attempts.Add(1)
+			out, md, err := next.HandleFinalize(ctx, in)
+			if isThrottle(err) {
+				t.throttled.Add(1)
+			}
+			return out, md, err
+		}), "Retry", middleware.After)
+}
+
+const (
+	throttleRetries  = 5
+	throttleBaseWait = 200 * time.Millisecond
+	throttleMaxWait  = 20 * time.Second
+)
+
+// backoff sleeps for a full-jitter exponential delay before retry attempt
+// (starting at 0), returning early if ctx is done.
+func (c *Client) backoff(ctx context.Context, attempt int) error {
+	wait := min(throttleBaseWait<<attempt, throttleMaxWait)
+	timer := time.NewTimer(rand.N(wait) + time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		c.throttle.retries.Add(1)
+		return nil
+	}
+}
+
+// retryThrottled runs fn again with backoff while it fails with a
+// throttling error that outlived the SDK's own retries.
+func (c *Client) retryThrottled(ctx context.Context, fn func() error) error {
+	err := fn()
+	for attempt := 0; attempt < throttleRetries && isThrottle(err); attempt++ {
+		if werr := c.backoff(ctx, attempt); werr != nil {
+			return err
+		}
+		err = fn()
+	}
+	return err
+}
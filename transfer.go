@@ -0,0 +1,111 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// upload runs the transfer manager and makes sure a failed multipart
+// upload is aborted. The manager aborts with the caller's context, which
+// does nothing once that context is canceled.
+func (c *Client) upload(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*manager.Uploader)) (*manager.UploadOutput, error) {
+	ctx, done, err := c.drain.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	output, err := c.uploader.Upload(ctx, input, optFns...)
+	var failure manager.MultiUploadFailure
+	if err != nil && ctx.Err() != nil && errors.As(err, &failure) {
+		c.abortMultipart(ctx, aws.ToString(input.Bucket), aws.ToString(input.Key), aws.String(failure.UploadID()))
+	}
+	return output, err
+}
+
+const defaultMultipartThreshold = 16 << 20
+
+// Upload stores body with a single PutObject when it is smaller than the
+// multipart threshold (Config.MultipartThreshold or WithMultipartThreshold,
+// 16 MiB by default) and with the concurrent multipart uploader otherwise.
+// Bodies of unknown size are buffered up to the threshold to decide.
+func (c *Client) Upload(ctx context.Context, bucket, key string, body io.Reader, contentType string, opts ...CallOption) error {
+	ctx, o := c.callOptions(ctx, opts)
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	c.applyCacheControl(input)
+	c.invalidate(bucket, key)
+	size := readerSize(body)
+	if o.compression != CompressionNone {
+		return c.putCompressed(ctx, input, size, o)
+	}
+
+	threshold := o.multipartThreshold
+	if threshold <= 0 {
+		threshold = defaultMultipartThreshold
+	}
+	if size < 0 {
+		head, err := io.ReadAll(io.LimitReader(body, threshold))
+		if err != nil {
+			return err
+		}
+		if int64(len(head)) < threshold {
+			size = int64(len(head))
+			input.Body = bytes.NewReader(head)
+		} else {
+			input.Body = io.MultiReader(bytes.NewReader(head), body)
+		}
+	}
+	if size >= 0 && size < threshold {
+		input.ContentLength = aws.Int64(size)
+		_, err := c.s3Client.PutObject(ctx, input)
+		return err
+	}
+	_, err := c.upload(ctx, input, o.uploaderOptions)
+	return err
+}
+
+// writeFile calls write with a temporary file next to localPath and
+// renames it into place only when write succeeds, so a failed or canceled
+// download leaves any existing file untouched.
+func writeFile(localPath string, write func(*os.File) error) (err error) {
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(localPath); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	dir, base := filepath.Split(localPath)
+	if dir == "" {
+		dir = "."
+	}
+	file, err := os.CreateTemp(dir, "."+base+".*.part")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+	if err = write(file); err != nil {
+		return err
+	}
+	if err = file.Chmod(mode); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), localPath)
+}
@@ -0,0 +1,138 @@
+package s3client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// TransportConfig tunes the HTTP transport. Zero fields keep the SDK
+// defaults, which allow only 10 idle connections per host.
+type TransportConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	DialTimeout         time.Duration
+	KeepAlive           time.Duration
+	TLSHandshakeTimeout time.Duration
+	// DisableHTTP2 restricts connections to HTTP/1.1. ForceHTTP2 attempts
+	// HTTP/2 even when a custom dialer or TLS config is in use.
+	DisableHTTP2 bool
+	ForceHTTP2   bool
+
+	// CAFile adds the PEM certificates it holds to the system roots, for
+	// endpoints behind a private CA. CertFile and KeyFile present a client
+	// certificate.
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+func (tc TransportConfig) tlsConfig(tr *http.Transport) error {
+	if tc.CAFile == "" && tc.CertFile == "" && tc.KeyFile == "" && !tc.InsecureSkipVerify {
+		return nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if tr.TLSClientConfig != nil {
+		cfg = tr.TLSClientConfig.Clone()
+	}
+	if tc.CAFile != "" {
+		pem, err := os.ReadFile(tc.CAFile)
+		if err != nil {
+			return err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("s3client: no certificates in %s", tc.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if tc.CertFile != "" || tc.KeyFile != "" {
+		if tc.CertFile == "" || tc.KeyFile == "" {
+			return errors.New("s3client: CertFile and KeyFile must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile)
+		if err != nil {
+			return err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	cfg.InsecureSkipVerify = tc.InsecureSkipVerify
+	tr.TLSClientConfig = cfg
+	return nil
+}
+
+func (tc TransportConfig) apply(tr *http.Transport) {
+	if tc.MaxIdleConns > 0 {
+		tr.MaxIdleConns = tc.MaxIdleConns
+	}
+	if tc.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = tc.MaxIdleConnsPerHost
+		tr.MaxIdleConns = max(tr.MaxIdleConns, tc.MaxIdleConnsPerHost)
+	}
+	if tc.MaxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = tc.MaxConnsPerHost
+	}
+	if tc.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = tc.IdleConnTimeout
+	}
+	if tc.TLSHandshakeTimeout > 0 {
+		tr.TLSHandshakeTimeout = tc.TLSHandshakeTimeout
+	}
+	switch {
+	case tc.DisableHTTP2:
+		tr.ForceAttemptHTTP2 = false
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case tc.ForceHTTP2:
+		tr.ForceAttemptHTTP2 = true
+	}
+}
+
+// NewHTTPClient returns an HTTP client with the transport settings of cfg,
+// for packages like admin that reach the same endpoint outside the S3 API.
+func NewHTTPClient(cfg Config) (*http.Client, error) {
+	client, _, err := newHTTPClient(cfg.Transport)
+	return client, err
+}
+
+// newHTTPClient keeps a handle on the transport so Close can release its
+// pooled connections. It starts from the SDK's transport defaults and, like
+// the SDK client, does not follow redirects.
+func newHTTPClient(tc *TransportConfig) (*http.Client, *http.Transport, error) {
+	builder := awshttp.NewBuildableClient()
+	if tc != nil && (tc.DialTimeout > 0 || tc.KeepAlive > 0) {
+		builder = builder.WithDialerOptions(func(d *net.Dialer) {
+			if tc.DialTimeout > 0 {
+				d.Timeout = tc.DialTimeout
+			}
+			if tc.KeepAlive > 0 {
+				d.KeepAlive = tc.KeepAlive
+			}
+		})
+	}
+	tr := builder.GetTransport()
+	if tc != nil {
+		tc.apply(tr)
+		if err := tc.tlsConfig(tr); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &http.Client{
+		Transport: tr,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, tr, nil
+}
@@ -0,0 +1,116 @@
+package s3client
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+)
+
+// MetaContentSHA256 records the SHA-256 of the uploaded file before any
+// compression. Uploads with WithSkipUnchanged write it.
+const MetaContentSHA256 = "content-sha256"
+
+// WithSkipUnchanged makes file uploads compare the local file with the
+// remote object first and skip the upload when size and hash match.
+func WithSkipUnchanged() CallOption {
+	return func(o *callOptions) {
+		o.skipUnchanged = true
+	}
+}
+
+type fileDigest struct {
+	size   int64
+	md5    string
+	sha256 string
+}
+
+func digestFile(file *os.File) (fileDigest, error) {
+	m, s := md5.New(), sha256.New()
+	n, err := io.Copy(io.MultiWriter(m, s), file)
+	if err != nil {
+		return fileDigest{}, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fileDigest{}, err
+	}
+	return fileDigest{
+		size:   n,
+		md5:    hex.EncodeToString(m.Sum(nil)),
+		sha256: hex.EncodeToString(s.Sum(nil)),
+	}, nil
+}
+
+// unchanged reports whether info describes the same content as the local
+// file. The recorded SHA-256 is preferred; otherwise the ETag is compared,
+// which for multipart objects means recomputing it for likely part sizes.
+func (d fileDigest) unchanged(file *os.File, info ObjectInfo, partSize int64) (bool, error) {
+	size := info.Size
+	if s, ok := info.Metadata[MetaUncompressedSize]; ok {
+		size, _ = strconv.ParseInt(s, 10, 64)
+	}
+	if size != d.size {
+		return false, nil
+	}
+	if sum, ok := info.Metadata[MetaContentSHA256]; ok {
+		return sum == d.sha256, nil
+	}
+	if isCompressed(info.ContentEncoding) {
+		return false, nil
+	}
+	etag := strings.Trim(info.ETag, `"`)
+	dash := strings.IndexByte(etag, '-')
+	if dash < 0 {
+		return etag == d.md5, nil
+	}
+	parts, err := strconv.ParseInt(etag[dash+1:], 10, 64)
+	if err != nil || parts <= 0 {
+		return false, nil
+	}
+	const mib = 1 << 20
+	guess := (d.size + parts - 1) / parts
+	guess = (guess + mib - 1) / mib * mib
+	for _, ps := range []int64{partSize, manager.DefaultUploadPartSize, guess} {
+		if ps <= 0 || (d.size+ps-1)/ps != parts {
+			continue
+		}
+		local, err := multipartETag(file, ps)
+		if err != nil {
+			return false, err
+		}
+		if local == etag {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func multipartETag(file *os.File, partSize int64) (string, error) {
+	defer file.Seek(0, io.SeekStart)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	all := md5.New()
+	var parts int
+	for {
+		h := md5.New()
+		n, err := io.CopyN(h, file, partSize)
+		if n > 0 {
+			all.Write(h.Sum(nil))
+			parts++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(all.Sum(nil)), parts), nil
+}
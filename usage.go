@@ -0,0 +1,96 @@
+package s3client
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type Usage struct {
+	Objects int64
+	Bytes   int64
+}
+
+func (u *Usage) add(size int64) {
+	u.Objects++
+	u.Bytes += size
+}
+
+type BucketUsage struct {
+	Usage
+	// Prefixes breaks the total down by the first "/"-terminated segment
+	// below the requested prefix. Objects directly under the prefix are
+	// only counted in the total.
+	Prefixes map[string]Usage
+}
+
+func (c *Client) GetBucketUsage(ctx context.Context, bucket, prefix string, breakdown bool) (BucketUsage, error) {
+	var usage BucketUsage
+	if breakdown {
+		usage.Prefixes = map[string]Usage{}
+	}
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		size := aws.ToInt64(obj.Size)
+		usage.add(size)
+		if breakdown {
+			rest := strings.TrimPrefix(*obj.Key, prefix)
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				p := prefix + rest[:i+1]
+				u := usage.Prefixes[p]
+				u.add(size)
+				usage.Prefixes[p] = u
+			}
+		}
+		return nil
+	})
+	return usage, err
+}
+
+type DUEntry struct {
+	Prefix string
+	Depth  int
+	Usage
+}
+
+// DU aggregates object counts and sizes for prefix and every sub-prefix up
+// to depth levels below it, like du -d. Each entry includes everything
+// nested beneath it. Entries are sorted by prefix.
+func (c *Client) DU(ctx context.Context, bucket, prefix string, depth int) ([]DUEntry, error) {
+	totals := map[string]*DUEntry{prefix: {Prefix: prefix}}
+	err := c.listObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		size := aws.ToInt64(obj.Size)
+		totals[prefix].add(size)
+		rest := strings.TrimPrefix(*obj.Key, prefix)
+		p := prefix
+		for d := 1; d <= depth; d++ {
+			i := strings.IndexByte(rest, '/')
+			if i < 0 {
+				break
+			}
+			p += rest[:i+1]
+			rest = rest[i+1:]
+			e, ok := totals[p]
+			if !ok {
+				e = &DUEntry{Prefix: p, Depth: d}
+				totals[p] = e
+			}
+			e.add(size)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]DUEntry, 0, len(totals))
+	for _, e := range totals {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Prefix < entries[j].Prefix
+	})
+	return entries, nil
+}
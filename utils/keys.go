@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxKeyLength = 1024
+
+var ErrInvalidBucketName = errors.New("s3client: invalid bucket name")
+
+var (
+	reservedBucketPrefixes = []string{"xn--", "sthree-", "amzn-s3-demo-"}
+	reservedBucketSuffixes = []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3", "--table-s3"}
+)
+
+// ValidateBucketName checks name against the S3 general purpose bucket
+// naming rules.
+func ValidateBucketName(name string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidBucketName, name, reason)
+	}
+	if len(name) < 3 || len(name) > 63 {
+		return invalid("must be 3 to 63 characters long")
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '.' || ch == '-') {
+			return invalid("only lowercase letters, digits, dots and hyphens are allowed")
+		}
+	}
+	if !isAlnum(name[0]) || !isAlnum(name[len(name)-1]) {
+		return invalid("must begin and end with a letter or digit")
+	}
+	if strings.Contains(name, "..") {
+		return invalid("must not contain adjacent dots")
+	}
+	if net.ParseIP(name) != nil {
+		return invalid("must not be formatted as an IP address")
+	}
+	for _, p := range reservedBucketPrefixes {
+		if strings.HasPrefix(name, p) {
+			return invalid("reserved prefix " + p)
+		}
+	}
+	for _, s := range reservedBucketSuffixes {
+		if strings.HasSuffix(name, s) {
+			return invalid("reserved suffix " + s)
+		}
+	}
+	return nil
+}
+
+func isAlnum(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9'
+}
+
+// SanitizeKey turns a user-supplied file name or path into a safe key: it
+// normalizes to NFC, treats backslashes as separators, strips control
+// characters and invalid UTF-8, drops empty, "." and ".." segments and
+// trims the result to the 1024 byte key limit.
+func SanitizeKey(name string) string {
+	name = strings.ToValidUTF8(name, "")
+	name = norm.NFC.String(name)
+	name = strings.Map(func(r rune) rune {
+		if r == '\\' {
+			return '/'
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	var segments []string
+	for _, s := range strings.Split(name, "/") {
+		s = strings.TrimSpace(s)
+		if s == "" || s == "." || s == ".." {
+			continue
+		}
+		segments = append(segments, s)
+	}
+	return truncateKey(strings.Join(segments, "/"))
+}
+
+func truncateKey(key string) string {
+	if len(key) <= maxKeyLength {
+		return key
+	}
+	key = key[:maxKeyLength]
+	for !utf8.ValidString(key) {
+		key = key[:len(key)-1]
+	}
+	return key
+}
+
+// JoinKey joins key segments with exactly one slash between them and no
+// leading slash. Unlike path.Join it does not resolve "." or ".." and keeps
+// a trailing slash on the last part, so prefixes stay prefixes.
+func JoinKey(parts ...string) string {
+	var b strings.Builder
+	for _, p := range parts {
+		p = strings.TrimLeft(p, "/")
+		if p == "" {
+			continue
+		}
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "/") {
+			b.WriteByte('/')
+		}
+		b.WriteString(p)
+	}
+	key := b.String()
+	for strings.Contains(key, "//") {
+		key = strings.ReplaceAll(key, "//", "/")
+	}
+	return key
+}
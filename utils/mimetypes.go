@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadContentTypes registers every mapping found in a mime.types file
+// (one "type/subtype ext1 ext2 ..." entry per line, '#' starts a comment).
+func LoadContentTypes(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return LoadContentTypesFrom(f)
+}
+
+func LoadContentTypesFrom(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(fields[0], "/") {
+			continue
+		}
+		for _, ext := range fields[1:] {
+			RegisterContentType(ext, fields[0])
+		}
+	}
+	return scanner.Err()
+}
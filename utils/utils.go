@@ -1,18 +1,53 @@
 package utils
 
 import (
+	"mime"
 	"path"
 	"strings"
+	"sync"
+)
+
+var (
+	registryMu  sync.RWMutex
+	registryMap = map[string]string{}
 )
 
 func DetectContentType(filename string) string {
-	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+	ext := normalizeExt(path.Ext(filename))
+	if ext == "" {
+		return "application/octet-stream"
+	}
+	registryMu.RLock()
+	mimeType, ok := registryMap[ext]
+	registryMu.RUnlock()
+	if ok {
+		return mimeType
+	}
 	if mimeType, ok := contentTypeMap[ext]; ok {
 		return mimeType
 	}
+	if mimeType := mime.TypeByExtension("." + ext); mimeType != "" {
+		return mimeType
+	}
 	return "application/octet-stream"
 }
 
+// RegisterContentType maps ext (with or without the leading dot) to
+// mimeType. Registered types take precedence over the built-in table.
+func RegisterContentType(ext, mimeType string) {
+	ext = normalizeExt(ext)
+	if ext == "" || mimeType == "" {
+		return
+	}
+	registryMu.Lock()
+	registryMap[ext] = mimeType
+	registryMu.Unlock()
+}
+
+func normalizeExt(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+}
+
 var contentTypeMap = map[string]string{
 	"txt":          "text/plain",
 	"log":          "text/plain",
@@ -201,6 +236,8 @@ var contentTypeMap = map[string]string{
 	"db":       "application/vnd.sqlite3",
 	"sqlite":   "application/vnd.sqlite3",
 	"parquet":  "application/parquet",
+	"gpkg":     "application/geopackage+sqlite3",
+	"dcm":      "application/dicom",
 	"avro":     "application/avro",
 	"orc":      "application/orc",
 	"h5":       "application/x-hdf5",
@@ -0,0 +1,148 @@
+package s3client
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SyncReport lists relative paths that differ between a local directory
+// and a bucket prefix.
+type SyncReport struct {
+	Matched int `json:"matched"`
+	// Missing files exist locally but not in the bucket; Extra objects
+	// exist only in the bucket.
+	Missing   []string   `json:"missing,omitempty"`
+	Extra     []string   `json:"extra,omitempty"`
+	Differing []SyncDiff `json:"differing,omitempty"`
+}
+
+type SyncDiff struct {
+	Path       string `json:"path"`
+	Reason     string `json:"reason"` // "size", "checksum" or "symlink"
+	LocalSize  int64  `json:"local_size"`
+	RemoteSize int64  `json:"remote_size"`
+}
+
+// InSync reports whether the report found no differences.
+func (r SyncReport) InSync() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Differing) == 0
+}
+
+// VerifySync compares the files below localDir with the objects under
+// prefix by size and checksum, using the same rules as WithSkipUnchanged.
+// Symlinks are compared with the targets UploadDirectory records.
+func (c *Client) VerifySync(ctx context.Context, localDir, bucket, prefix string) (SyncReport, error) {
+	local := map[string]string{}
+	err := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !(d.Type().IsRegular() || d.Type()&fs.ModeSymlink != 0) {
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		local[filepath.ToSlash(rel)] = p
+		return nil
+	})
+	if err != nil {
+		return SyncReport{}, err
+	}
+
+	var (
+		report SyncReport
+		common []string
+	)
+	err = c.listFiltered(ctx, bucket, prefix, ListOptions{}, func(info ObjectInfo) error {
+		rel := strings.TrimPrefix(info.Key, prefix)
+		if strings.HasSuffix(rel, "/") {
+			return nil
+		}
+		if _, ok := local[rel]; ok {
+			common = append(common, rel)
+		} else {
+			report.Extra = append(report.Extra, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return SyncReport{}, err
+	}
+	seen := make(map[string]bool, len(common))
+	for _, rel := range common {
+		seen[rel] = true
+	}
+	for rel := range local {
+		if !seen[rel] {
+			report.Missing = append(report.Missing, rel)
+		}
+	}
+
+	var mu sync.Mutex
+	err = forEachConcurrent(len(common), manifestConcurrency, func(i int) error {
+		rel := common[i]
+		diff, err := c.compareFile(ctx, bucket, prefix+rel, local[rel])
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if diff == nil {
+			report.Matched++
+		} else {
+			diff.Path = rel
+			report.Differing = append(report.Differing, *diff)
+		}
+		return nil
+	})
+	sort.Strings(report.Missing)
+	sort.Strings(report.Extra)
+	sort.Slice(report.Differing, func(i, j int) bool { return report.Differing[i].Path < report.Differing[j].Path })
+	return report, err
+}
+
+func (c *Client) compareFile(ctx context.Context, bucket, key, localPath string) (*SyncDiff, error) {
+	info, err := c.StatObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(localPath)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(localPath)
+		if err != nil {
+			return nil, err
+		}
+		if info.Metadata[MetaSymlinkTarget] != target {
+			return &SyncDiff{Reason: "symlink", RemoteSize: info.Size}, nil
+		}
+		return nil, nil
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	digest, err := digestFile(file)
+	if err != nil {
+		return nil, err
+	}
+	same, err := digest.unchanged(file, info, 0)
+	if err != nil || same {
+		return nil, err
+	}
+	diff := &SyncDiff{Reason: "checksum", LocalSize: digest.size, RemoteSize: info.Size}
+	if digest.size != info.Size && !isCompressed(info.ContentEncoding) {
+		diff.Reason = "size"
+	}
+	return diff, nil
+}
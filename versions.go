@@ -0,0 +1,110 @@
+package s3client
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type objectVersion struct {
+	key          string
+	versionID    string
+	lastModified time.Time
+	latest       bool
+}
+
+// PruneVersions keeps the newest keepLatest versions of every key under
+// prefix, the current one included, and deletes older noncurrent
+// versions and delete markers. When olderThan is positive, only versions
+// last modified before now-olderThan are deleted. The current version is
+// never deleted. It returns the number of versions removed.
+func (c *Client) PruneVersions(ctx context.Context, bucket, prefix string, keepLatest int, olderThan time.Duration) (int64, error) {
+	keepLatest = max(keepLatest, 1)
+	var cutoff time.Time
+	if olderThan > 0 {
+		cutoff = time.Now().Add(-olderThan)
+	}
+	var (
+		deleted int64
+		ids     []types.ObjectIdentifier
+		lastKey string
+		rank    int
+	)
+	flush := func() error {
+		if err := c.deleteIdentifiers(ctx, bucket, ids); err != nil {
+			return err
+		}
+		deleted += int64(len(ids))
+		ids = ids[:0]
+		return nil
+	}
+	paginator := s3.NewListObjectVersionsPaginator(c.s3Client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, err
+		}
+		for _, v := range pageVersions(page) {
+			if v.key != lastKey {
+				lastKey, rank = v.key, 0
+			}
+			rank++
+			if v.latest || rank <= keepLatest {
+				continue
+			}
+			if !cutoff.IsZero() && !v.lastModified.Before(cutoff) {
+				continue
+			}
+			ids = append(ids, types.ObjectIdentifier{Key: aws.String(v.key), VersionId: aws.String(v.versionID)})
+		}
+		if len(ids) >= maxDeleteBatch {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	return deleted, flush()
+}
+
+// pageVersions merges the versions and delete markers of a page into key
+// order, newest first within a key.
+func pageVersions(page *s3.ListObjectVersionsOutput) []objectVersion {
+	var versions []objectVersion
+	for _, v := range page.Versions {
+		versions = append(versions, objectVersion{
+			key:          aws.ToString(v.Key),
+			versionID:    aws.ToString(v.VersionId),
+			lastModified: aws.ToTime(v.LastModified),
+			latest:       aws.ToBool(v.IsLatest),
+		})
+	}
+	for _, m := range page.DeleteMarkers {
+		versions = append(versions, objectVersion{
+			key:          aws.ToString(m.Key),
+			versionID:    aws.ToString(m.VersionId),
+			lastModified: aws.ToTime(m.LastModified),
+			latest:       aws.ToBool(m.IsLatest),
+		})
+	}
+	slices.SortStableFunc(versions, func(a, b objectVersion) int {
+		if n := cmp.Compare(a.key, b.key); n != 0 {
+			return n
+		}
+		if a.latest != b.latest {
+			if a.latest {
+				return -1
+			}
+			return 1
+		}
+		return b.lastModified.Compare(a.lastModified)
+	})
+	return versions
+}
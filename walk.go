@@ -0,0 +1,114 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	// SkipPrefix returned from a WalkFunc skips the remaining objects that
+	// share the "directory" of the current key.
+	SkipPrefix = errors.New("skip this prefix")
+	// SkipAll returned from a WalkFunc stops the walk without an error.
+	SkipAll = errors.New("skip everything")
+
+	errRestartWalk = errors.New("restart walk")
+)
+
+type WalkFunc func(info ObjectInfo) error
+
+// Walk calls fn for every object under prefix in key order, paging through
+// listings as needed.
+func (c *Client) Walk(ctx context.Context, bucket, prefix string, fn WalkFunc) error {
+	return c.walk(ctx, bucket, prefix, 0, fn)
+}
+
+// WalkDetailed is Walk with the metadata of each listing page fetched by
+// prefetch concurrent HeadObject requests before fn sees it, as with
+// ListOptions.Prefetch.
+func (c *Client) WalkDetailed(ctx context.Context, bucket, prefix string, prefetch int, fn WalkFunc) error {
+	return c.walk(ctx, bucket, prefix, max(prefetch, 1), fn)
+}
+
+func (c *Client) walk(ctx context.Context, bucket, prefix string, prefetch int, fn WalkFunc) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	for {
+		var skipped string
+		err := c.listPages(ctx, input, func(page *s3.ListObjectsV2Output) error {
+			var infos []ObjectInfo
+			for _, obj := range page.Contents {
+				if obj.Key != nil {
+					infos = append(infos, objectInfo(obj))
+				}
+			}
+			if prefetch > 0 {
+				if err := c.prefetch(ctx, bucket, infos, prefetch); err != nil {
+					return err
+				}
+			}
+			for _, info := range infos {
+				err := fn(info)
+				if err == nil {
+					continue
+				}
+				if !errors.Is(err, SkipPrefix) {
+					return err
+				}
+				dir := keyDir(info.Key)
+				if len(dir) <= len(prefix) {
+					return SkipAll
+				}
+				skipped = dir
+				return errRestartWalk
+			}
+			return nil
+		})
+		switch {
+		case errors.Is(err, errRestartWalk):
+			// U+10FFFF sorts after every valid UTF-8 continuation of dir.
+			input = &s3.ListObjectsV2Input{
+				Bucket:     aws.String(bucket),
+				Prefix:     aws.String(prefix),
+				StartAfter: aws.String(skipped + "\U0010FFFF"),
+			}
+		case errors.Is(err, SkipAll):
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+func keyDir(key string) string {
+	i := strings.LastIndexByte(key, '/')
+	if i < 0 {
+		return ""
+	}
+	return key[:i+1]
+}
+
+// prefetch adds the metadata only HeadObject returns to infos, going
+// through StatObject so the stat cache is warmed. Objects deleted since
+// they were listed keep their listing info.
+func (c *Client) prefetch(ctx context.Context, bucket string, infos []ObjectInfo, concurrency int) error {
+	return forEachConcurrent(len(infos), concurrency, func(i int) error {
+		head, err := c.StatObject(ctx, bucket, infos[i].Key)
+		if isNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		infos[i].ContentType = head.ContentType
+		infos[i].ContentEncoding = head.ContentEncoding
+		infos[i].VersionID = head.VersionID
+		infos[i].Metadata = head.Metadata
+		return nil
+	})
+}
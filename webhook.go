@@ -0,0 +1,303 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	WebhookSignatureHeader = "X-S3client-Signature"
+	WebhookTimestampHeader = "X-S3client-Timestamp"
+	WebhookDeliveryHeader  = "X-S3client-Delivery"
+)
+
+type WebhookOptions struct {
+	URL string
+	// Secret signs each delivery with HMAC-SHA256; see VerifyWebhook.
+	Secret []byte
+	// MaxAttempts per event defaults to 5, with exponential backoff from
+	// RetryWait, which defaults to one second.
+	MaxAttempts int
+	RetryWait   time.Duration
+	// Events that still fail are stored as JSON under DeadLetterPrefix in
+	// DeadLetterBucket, or dropped when no bucket is set.
+	DeadLetterBucket string
+	DeadLetterPrefix string
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+type WebhookStats struct {
+	Received     int64
+	Delivered    int64
+	Retries      int64
+	DeadLettered int64
+	Dropped      int64
+	// Lost counts events of a source without acknowledgement, such as
+	// ListenBucketEvents, that were neither delivered nor dead-lettered.
+	// Such a source does not redeliver them.
+	Lost      int64
+	Errors    int64
+	LastError error
+}
+
+// WebhookForwarder posts the events of an EventSource to a webhook, one
+// event per request. A batch is acknowledged once every event in it was
+// delivered, dead-lettered or dropped; otherwise the source redelivers it.
+// Sources without acknowledgement cannot, so events that fail are counted
+// as Lost and the rest of their batch is still forwarded.
+type WebhookForwarder struct {
+	c    *Client
+	src  EventSource
+	opts WebhookOptions
+
+	mu        sync.Mutex
+	stats     WebhookStats
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *Client) NewWebhookForwarder(src EventSource, opts WebhookOptions) *WebhookForwarder {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.RetryWait <= 0 {
+		opts.RetryWait = time.Second
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &WebhookForwarder{c: c, src: src, opts: opts}
+}
+
+// Start runs the forwarder in the background until Stop is called or the
+// client is closed.
+func (f *WebhookForwarder) Start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stop != nil {
+		return
+	}
+	f.stop, f.done = make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		defer cancel()
+		go func() {
+			<-stop
+			cancel()
+		}()
+		_ = f.Run(ctx)
+	}(f.stop, f.done)
+	f.closeOnce.Do(func() { f.c.onClose(f.Stop) })
+}
+
+func (f *WebhookForwarder) Stop() {
+	f.mu.Lock()
+	stop, done := f.stop, f.done
+	f.stop, f.done = nil, nil
+	f.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (f *WebhookForwarder) Stats() WebhookStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+// Run forwards events until ctx is done. Source errors are counted and
+// retried after RetryWait.
+func (f *WebhookForwarder) Run(ctx context.Context) error {
+	for {
+		events, ack, err := f.src.Next(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			f.fail(err)
+			if err := sleepCtx(ctx, f.opts.RetryWait); err != nil {
+				return err
+			}
+			continue
+		}
+		f.count(&f.stats.Received, int64(len(events)))
+		handled := true
+		for i, ev := range events {
+			err := f.forward(ctx, ev)
+			if err == nil {
+				continue
+			}
+			f.fail(err)
+			handled = false
+			if ack == nil {
+				f.count(&f.stats.Lost, 1)
+				if ctx.Err() == nil {
+					continue
+				}
+				f.count(&f.stats.Lost, int64(len(events)-i-1))
+			}
+			break
+		}
+		if handled && ack != nil {
+			if err := ack(ctx); err != nil {
+				f.fail(err)
+			}
+		}
+	}
+}
+
+// forward delivers ev or dead-letters it, and only fails when neither
+// succeeded.
+func (f *WebhookForwarder) forward(ctx context.Context, ev BucketEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	id := rand.Text()
+	wait := f.opts.RetryWait
+	for attempt := 1; ; attempt++ {
+		retry, err := f.deliver(ctx, id, body)
+		if err == nil {
+			f.count(&f.stats.Delivered, 1)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retry || attempt >= f.opts.MaxAttempts {
+			return f.deadLetter(ctx, id, ev, attempt, err)
+		}
+		f.count(&f.stats.Retries, 1)
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+		wait *= 2
+	}
+}
+
+// deliver reports whether a failed delivery is worth retrying.
+func (f *WebhookForwarder) deliver(ctx context.Context, id string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookDeliveryHeader, id)
+	req.Header.Set(WebhookTimestampHeader, ts)
+	if len(f.opts.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, webhookSignature(f.opts.Secret, ts, body))
+	}
+	resp, err := f.opts.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("s3client: webhook returned HTTP %d", resp.StatusCode)
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return retry, err
+}
+
+func (f *WebhookForwarder) deadLetter(ctx context.Context, id string, ev BucketEvent, attempts int, cause error) error {
+	if f.opts.DeadLetterBucket == "" {
+		f.count(&f.stats.Dropped, 1)
+		f.fail(cause)
+		return nil
+	}
+	data, err := json.Marshal(map[string]any{
+		"event":    ev,
+		"error":    cause.Error(),
+		"attempts": attempts,
+		"failed":   time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	key := f.opts.DeadLetterPrefix + time.Now().UTC().Format("2006/01/02/150405") + "-" + id + ".json"
+	if err := f.c.PutObjectBytes(ctx, f.opts.DeadLetterBucket, key, data, "application/json", WithCompression(CompressionNone)); err != nil {
+		return errors.Join(cause, err)
+	}
+	f.count(&f.stats.DeadLettered, 1)
+	return nil
+}
+
+func (f *WebhookForwarder) count(counter *int64, n int64) {
+	f.mu.Lock()
+	*counter += n
+	f.mu.Unlock()
+}
+
+func (f *WebhookForwarder) fail(err error) {
+	f.mu.Lock()
+	f.stats.Errors++
+	f.stats.LastError = err
+	f.mu.Unlock()
+}
+
+func webhookSignature(secret []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature of a request sent by a
+// WebhookForwarder and returns its event. Timestamps more than maxAge
+// away from now are rejected; zero disables that check.
+func VerifyWebhook(r *http.Request, secret []byte, maxAge time.Duration) (BucketEvent, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BucketEvent{}, err
+	}
+	ts := r.Header.Get(WebhookTimestampHeader)
+	want := webhookSignature(secret, ts, body)
+	if !hmac.Equal([]byte(want), []byte(r.Header.Get(WebhookSignatureHeader))) {
+		return BucketEvent{}, errors.New("s3client: invalid webhook signature")
+	}
+	if maxAge > 0 {
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return BucketEvent{}, fmt.Errorf("s3client: invalid webhook timestamp: %w", err)
+		}
+		if d := time.Since(time.Unix(sec, 0)); d > maxAge || d < -maxAge {
+			return BucketEvent{}, errors.New("s3client: webhook timestamp outside the accepted window")
+		}
+	}
+	var ev BucketEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return BucketEvent{}, err
+	}
+	return ev, nil
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}